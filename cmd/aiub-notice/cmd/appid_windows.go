@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
@@ -18,7 +20,8 @@ var appidCmd = &cobra.Command{
 	Short:   "Manage AppID registration for Windows notifications",
 	Long: `The appid command allows you to register or unregister the application
 AppUserModelID (AUMID) in the Windows registry. This is necessary for sending
-toast notifications on Windows.
+toast notifications on Windows. Registering also adds the aiub-notice: URL
+protocol, through which clicking a toast opens the notice and marks it read.
 
 Examples:
 	# Register the application appid
@@ -28,6 +31,9 @@ Examples:
 	aiub-notice appid --unregister`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if register, _ := cmd.Flags().GetBool("register"); register {
+			if _, err := loadConfig(cmd); err != nil {
+				return err
+			}
 			iconPath, err := common.GetIconPath()
 			if err != nil {
 				return fmt.Errorf("getting icon path: %w", err)
@@ -36,10 +42,22 @@ Examples:
 				return fmt.Errorf("registering appid: %w", err)
 			}
 			logger.L().Info("successfully registered AIUB Notice toast application", slog.String("appid", common.AppID))
+
+			launcher, err := launcherPath()
+			if err != nil {
+				return err
+			}
+			if err := appid.RegisterProtocol(common.URLScheme, common.DisplayName, launcher); err != nil {
+				return fmt.Errorf("registering URL protocol: %w", err)
+			}
+			logger.L().Info("successfully registered URL protocol for opening notices", slog.String("scheme", common.URLScheme))
 		} else if unregister, _ := cmd.Flags().GetBool("unregister"); unregister {
 			if err := appid.Unregister(common.AppID); err != nil {
 				return fmt.Errorf("unregistering appid: %w", err)
 			}
+			if err := appid.UnregisterProtocol(common.URLScheme); err != nil {
+				return fmt.Errorf("unregistering URL protocol: %w", err)
+			}
 			logger.L().Info("successfully unregistered AIUB Notice toast application", slog.String("appid", common.AppID))
 		} else {
 			_ = cmd.Help()
@@ -48,6 +66,25 @@ Examples:
 	},
 }
 
+// launcherPath returns the launcher next to the executable, which runs
+// commands without opening a console window, or the executable itself if
+// there is no launcher.
+func launcherPath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("get executable path: %w", err)
+	}
+	exe, err = filepath.Abs(exe)
+	if err != nil {
+		return "", fmt.Errorf("get absolute path of executable: %w", err)
+	}
+	launcher := filepath.Join(filepath.Dir(exe), common.LauncherName+".exe")
+	if _, err := os.Stat(launcher); err != nil {
+		return exe, nil
+	}
+	return launcher, nil
+}
+
 func init() {
 	rootCmd.AddCommand(appidCmd)
 
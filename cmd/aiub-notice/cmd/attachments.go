@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// attachmentsCmd represents the attachments command
+var attachmentsCmd = &cobra.Command{
+	Use:     "attachments",
+	Aliases: []string{"files"},
+	Short:   "List, open and re-download notice attachments",
+	Long: `This command manages files (PDFs, images, documents) linked from notice pages.
+Attachments are downloaded when a new notice is fetched and kept in a local
+archive, so they stay available after the notice is taken down.
+
+Examples:
+	# list all known attachments
+	aiub-notice attachments
+
+	# open the 3rd attachment from the list
+	aiub-notice attachments --open 3
+
+	# download attachments missing from the local archive
+	aiub-notice attachments --redownload`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if redownload, _ := cmd.Flags().GetBool("redownload"); redownload {
+			if _, err := loadConfig(cmd); err != nil {
+				return err
+			}
+			force, _ := cmd.Flags().GetBool("force")
+			count, err := notice.RedownloadAttachments(cmd.Context(), force)
+			if err != nil {
+				return fmt.Errorf("re-downloading attachments: %w", err)
+			}
+			logger.L().Info("attachments downloaded", slog.Int("count", count))
+			return nil
+		}
+
+		notices, err := notice.GetCachedNotices()
+		if err != nil {
+			return fmt.Errorf("fetching cached notices: %w", err)
+		}
+
+		type entry struct {
+			notice     notice.Notice
+			attachment notice.Attachment
+		}
+		var entries []entry
+		for _, n := range notices {
+			for _, a := range n.Attachments {
+				entries = append(entries, entry{notice: n, attachment: a})
+			}
+		}
+
+		if open, _ := cmd.Flags().GetInt("open"); open > 0 {
+			if open > len(entries) {
+				return fmt.Errorf("attachment %d does not exist, there are %d attachments", open, len(entries))
+			}
+			a := entries[open-1].attachment
+			path, err := notice.AttachmentPath(a)
+			if err == nil {
+				_, err = os.Stat(path)
+			}
+			if err != nil {
+				if !common.IsWebURL(a.URL) {
+					return fmt.Errorf("attachment is not stored locally and its link is not a web page: %q", a.URL)
+				}
+				logger.L().Warn("attachment is not stored locally, opening original link", slog.String("url", a.URL))
+				path = a.URL
+			}
+			if err := common.OpenURL(path); err != nil {
+				return fmt.Errorf("opening attachment: %w", err)
+			}
+			return nil
+		}
+
+		if len(entries) == 0 {
+			logger.L().Info("no attachments found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "#\tNAME\tSIZE\tDATE\tNOTICE")
+		for i, e := range entries {
+			size := "not stored"
+			if e.attachment.Downloaded() {
+				size = formatSize(e.attachment.Size)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+				i+1, e.attachment.Name, size, e.notice.DateText(), e.notice.Title)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachmentsCmd)
+
+	attachmentsCmd.Flags().IntP("open", "o", 0, "Open the attachment with the given number")
+	attachmentsCmd.Flags().Bool("redownload", false, "Download attachments missing from the local archive")
+	attachmentsCmd.Flags().Bool("force", false, "Re-download all attachments [Used with --redownload]")
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// backfillCmd represents the backfill command
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Fetch the full notice archive into the local cache",
+	Long: `This command crawls every page of the AIUB notice archive and stores the notices
+in the local cache, so that list and last can show the full history. Crawled
+notices are marked as seen and will not trigger notifications.
+
+An interrupted backfill resumes from the next unfetched page. A finished
+backfill only checks the page past the end of the archive again.
+
+Examples:
+	# crawl the archive, resuming a previous run if any
+	aiub-notice backfill
+
+	# fetch at most 5 more pages
+	aiub-notice backfill --max-pages 5
+
+	# start over from the first page
+	aiub-notice backfill --restart
+
+	# backfill another configured source
+	aiub-notice backfill --source mirror`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		name, _ := cmd.Flags().GetString("source")
+		if name == "" {
+			name = notice.DefaultSource.Name
+		}
+		src, err := cfg.Source(name)
+		if err != nil {
+			return err
+		}
+		if src.Kind != notice.KindHTML {
+			return fmt.Errorf("source %q is a %s source, only %s sources have a paginated archive", src.Name, src.Kind, notice.KindHTML)
+		}
+
+		if restart, _ := cmd.Flags().GetBool("restart"); restart {
+			if err := notice.ResetBackfillState(src.Name); err != nil {
+				return fmt.Errorf("resetting backfill state: %w", err)
+			}
+		}
+
+		maxPages, err := cmd.Flags().GetInt("max-pages")
+		if err != nil {
+			return fmt.Errorf("parsing max-pages flag: %w", err)
+		}
+		delay, err := cmd.Flags().GetDuration("delay")
+		if err != nil {
+			return fmt.Errorf("parsing delay flag: %w", err)
+		}
+
+		state, err := notice.Backfill(cmd.Context(), src, notice.BackfillOptions{
+			MaxPages: maxPages,
+			Delay:    delay,
+			Progress: func(page, count int) {
+				logger.L().Info("fetched notice page", slog.Int("page", page), slog.Int("notices", count))
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("backfilling notices: %w", err)
+		}
+
+		if state.Done {
+			logger.L().Info("backfill complete", slog.Int("notices", state.Fetched))
+		} else {
+			logger.L().Info("backfill paused, run again to continue",
+				slog.Int("next_page", state.NextPage),
+				slog.Int("notices", state.Fetched),
+			)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backfillCmd)
+
+	backfillCmd.Flags().String("source", "", "Name of the configured source to backfill")
+	backfillCmd.Flags().Bool("restart", false, "Discard saved progress and start from the first page")
+	backfillCmd.Flags().Int("max-pages", 0, "Maximum number of pages to fetch in this run (0 for no limit)")
+	backfillCmd.Flags().Duration("delay", time.Second, "Delay between page requests")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/config"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:     "config",
+	Aliases: []string{"cfg"},
+	Short:   "Show or initialize the configuration file",
+	Long: `This command prints the effective configuration, including built-in defaults
+for anything not set in the configuration file.
+
+The configuration file defines the notice sources to watch: base URL, listing
+path, CSS selectors, date layouts and link attribute. A source entry only needs
+the fields that differ from the built-in AIUB source, e.g. to use a local mirror:
+
+	{"sources": [{"name": "mirror", "base_url": "http://localhost:8080"}]}
+
+Examples:
+	# print the effective configuration
+	aiub-notice config
+
+	# print the configuration file path
+	aiub-notice config --path
+
+	# write the default configuration to the configuration file
+	aiub-notice config --init`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configPath(cmd)
+		if err != nil {
+			return err
+		}
+
+		if showPath, _ := cmd.Flags().GetBool("path"); showPath {
+			fmt.Println(path)
+			return nil
+		}
+
+		if initialize, _ := cmd.Flags().GetBool("init"); initialize {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("config file already exists: %s", path)
+			}
+			if err := config.Default().Write(path); err != nil {
+				return fmt.Errorf("writing config file: %w", err)
+			}
+			logger.L().Info("wrote default configuration", slog.String("path", path))
+			return nil
+		}
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		return cfg.Encode(os.Stdout)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	configCmd.Flags().Bool("path", false, "Print the configuration file path")
+	configCmd.Flags().Bool("init", false, "Write the default configuration file")
+}
+
+func configPath(cmd *cobra.Command) (string, error) {
+	override, _ := cmd.Flags().GetString("config")
+	path, err := config.Path(override)
+	if err != nil {
+		return "", fmt.Errorf("getting config path: %w", err)
+	}
+	return path, nil
+}
+
+// loadConfig loads the configuration selected by the --config flag.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	path, err := configPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	notice.SetRetryPolicy(cfg.Retry)
+	if err := common.SetHTTPConfig(cfg.HTTP); err != nil {
+		return nil, fmt.Errorf("configuring HTTP client: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// debugCmd groups troubleshooting commands
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Troubleshoot fetching and parsing of notices",
+}
+
+// debugFetchCmd represents the debug fetch command
+var debugFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Fetch a listing page and show what the parser found",
+	Long: `This command fetches one page of a source's listing and prints every notice the
+parser extracted along with the fields it could not parse. Nothing is cached and
+no notifications are sent.
+
+Examples:
+	# inspect the first page of the default source
+	aiub-notice debug fetch
+
+	# inspect page 3 of the news source and keep its HTML
+	aiub-notice debug fetch --source news --page 3 --dump`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		name, _ := cmd.Flags().GetString("source")
+		if name == "" {
+			name = notice.DefaultSource.Name
+		}
+		src, err := cfg.Source(name)
+		if err != nil {
+			return err
+		}
+		if src.Kind != notice.KindHTML {
+			return fmt.Errorf("source %q is a %s source, only %s sources can be inspected", src.Name, src.Kind, notice.KindHTML)
+		}
+		page, _ := cmd.Flags().GetInt("page")
+		if page < 1 {
+			return fmt.Errorf("page must be at least 1")
+		}
+
+		report, body, fetchErr := notice.InspectPage(cmd.Context(), src, page)
+		if dump, _ := cmd.Flags().GetBool("dump"); dump && body != nil {
+			path, err := notice.DumpHTML(src.Name, page, body)
+			if err != nil {
+				return fmt.Errorf("dumping HTML: %w", err)
+			}
+			fmt.Printf("HTML saved to %s\n", path)
+		}
+		if fetchErr != nil {
+			return fmt.Errorf("fetching %s: %w", report.URL, fetchErr)
+		}
+
+		fmt.Printf("URL:     %s\n", report.URL)
+		fmt.Printf("Items:   %d matched by %q\n", report.Items, src.Selectors.Item)
+		fmt.Printf("Notices: %d parsed\n", len(report.Notices))
+		fmt.Printf("Errors:  %d\n\n", len(report.Errors))
+
+		if len(report.Notices) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ID\tDATE\tTITLE\tLINK")
+			for _, n := range report.Notices {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", n.ID, n.DateText(), n.Title, n.Link)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if len(report.Errors) > 0 {
+			fmt.Println("\nField errors:")
+			for _, fe := range report.Errors {
+				fmt.Printf("  %s\n", fe.Error())
+			}
+		}
+
+		if err := report.Err(); err != nil {
+			return fmt.Errorf("page %d: %w", page, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+	debugCmd.AddCommand(debugFetchCmd)
+
+	debugFetchCmd.Flags().String("source", "", "Name of the configured source to fetch")
+	debugFetchCmd.Flags().Int("page", 1, "Listing page to fetch")
+	debugFetchCmd.Flags().Bool("dump", false, "Save the fetched HTML to the data directory")
+}
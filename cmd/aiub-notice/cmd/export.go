@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [keywords]",
+	Short: "Export cached notices as JSON, CSV, Markdown or HTML",
+	Long: `This command writes the cached notices, newest first, to standard output or to a
+file. Keywords limit the export to notices containing every word, as in search;
+words in double quotes must appear together. It works offline on the local
+cache.
+
+Without --format the format is taken from the extension of the output file,
+and is JSON otherwise.
+
+Examples:
+	# copy this week's notices into a group chat
+	aiub-notice export --since 2025-03-01 --format markdown
+
+	# export all fee notices to a spreadsheet
+	aiub-notice export fees -o fees.csv
+
+	# save the news of a semester as a web page
+	aiub-notice export --category news --since 2025-01-01 --until 2025-06-30 -o news.html`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		q := notice.ParseQuery(strings.Join(args, " "))
+
+		if err := parseDayFlags(cmd, &q); err != nil {
+			return err
+		}
+		q.Category, _ = cmd.Flags().GetString("category")
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		if format == "" {
+			format = exportFormatOf(output)
+		}
+
+		notices, err := notice.GetCachedNotices()
+		if err != nil {
+			return fmt.Errorf("loading cached notices: %w", err)
+		}
+		var matched []notice.Notice
+		for _, n := range notices {
+			if q.Matches(n) {
+				matched = append(matched, n)
+			}
+		}
+
+		if output == "" {
+			return notice.Export(os.Stdout, format, matched)
+		}
+		var buf bytes.Buffer
+		if err := notice.Export(&buf, format, matched); err != nil {
+			return err
+		}
+		if err := common.WriteFileAtomic(output, buf.Bytes(), 0o644); err != nil {
+			return fmt.Errorf("writing export: %w", err)
+		}
+		logger.L().Info("exported notices",
+			slog.Int("count", len(matched)),
+			slog.String("format", format),
+			slog.String("file", output),
+		)
+		return nil
+	},
+}
+
+// exportFormatOf returns the export format matching the extension of path.
+func exportFormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return notice.FormatCSV
+	case ".md", ".markdown":
+		return notice.FormatMarkdown
+	case ".html", ".htm":
+		return notice.FormatHTML
+	default:
+		return notice.FormatJSON
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringP("format", "f", "", "Output format: "+strings.Join(notice.ExportFormats, ", "))
+	exportCmd.Flags().StringP("output", "o", "", "Write to this file instead of standard output")
+	exportCmd.Flags().String("since", "", "Only export notices dated on or after this day (YYYY-MM-DD)")
+	exportCmd.Flags().String("until", "", "Only export notices dated on or before this day (YYYY-MM-DD)")
+	exportCmd.Flags().StringP("category", "c", "", "Only export notices of this category")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [id]",
+	Short: "Show how an edited notice changed over time",
+	Long: `This command shows the recorded revisions of a notice that was edited after it
+was first fetched, with a line diff between consecutive revisions. Without an
+ID it lists the notices that have revisions. An ID may be shortened to any
+unique prefix.
+
+Examples:
+	# list edited notices
+	aiub-notice history
+
+	# show the changes of a notice
+	aiub-notice history 3fa9c2e1`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := notice.RevisedNotices()
+		if err != nil {
+			return fmt.Errorf("listing revised notices: %w", err)
+		}
+
+		if len(args) == 0 {
+			if len(ids) == 0 {
+				logger.L().Info("no edited notices recorded")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ID\tREVISIONS\tLAST CHANGE\tTITLE")
+			for _, id := range ids {
+				revisions, err := notice.LoadRevisions(id)
+				if err != nil || len(revisions) == 0 {
+					continue
+				}
+				last := revisions[len(revisions)-1]
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+					id, len(revisions), last.RecordedAt.Format("02 Jan 2006 15:04"), last.Title)
+			}
+			return w.Flush()
+		}
+
+		var matches []string
+		for _, id := range ids {
+			if strings.HasPrefix(id, args[0]) {
+				matches = append(matches, id)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("no revisions recorded for notice %q", args[0])
+		case 1:
+		default:
+			return fmt.Errorf("notice ID %q is ambiguous, it matches %s", args[0], strings.Join(matches, ", "))
+		}
+
+		revisions, err := notice.LoadRevisions(matches[0])
+		if err != nil {
+			return fmt.Errorf("loading revisions: %w", err)
+		}
+
+		for i, r := range revisions {
+			fmt.Printf("Revision %d, recorded %s\n", i+1, r.RecordedAt.Format("02 Jan 2006 15:04"))
+			if i == 0 {
+				for _, line := range r.Text() {
+					fmt.Println("  " + line)
+				}
+			} else {
+				for _, line := range notice.DiffLines(revisions[i-1].Text(), r.Text()) {
+					fmt.Println(line)
+				}
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
@@ -36,11 +36,15 @@ Examples:
 			numsMap[n] = struct{}{}
 		}
 
-		seen, err := notice.LoadSeenNotices()
+		store, err := notice.DefaultStore()
+		if err != nil {
+			return err
+		}
+		seen, err := notice.LoadSeen(store)
 		if err != nil {
 			return fmt.Errorf("loading seen notices: %w", err)
 		}
-		if len(seen) == 0 {
+		if seen.IsEmpty() {
 			logger.L().Warn("no notices have been fetched yet")
 			return nil
 		}
@@ -63,12 +67,13 @@ Examples:
 			if _, ok := numsMap[idx+1]; !ok {
 				continue
 			}
-			if _, ok := seen[n.Link]; ok {
-				if err := toast.Show(n); err != nil {
-					return fmt.Errorf("showing toast: %w", err)
-				}
-				logger.L().Info("triggered toast for notice", slog.String("title", n.Title), slog.String("link", n.Link))
+			if !seen.Has(n) {
+				continue
+			}
+			if err := toast.Show(n); err != nil {
+				return fmt.Errorf("showing toast: %w", err)
 			}
+			logger.L().Info("triggered toast for notice", slog.String("title", n.Title), slog.String("link", n.Link))
 		}
 
 		return nil
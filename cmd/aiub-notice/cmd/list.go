@@ -14,7 +14,10 @@ var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls", "show"},
 	Short:   "List all fetched notices",
-	Long:    `Display all fetched notices in an interactive table that allows navigation and opening notices.`,
+	Long: `Display all fetched notices in an interactive table that allows navigation and
+opening notices. Unread notices are shown in bold; notices can be marked read,
+starred and annotated with notes from the table, which can also be limited to
+starred notices.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		p := tea.NewProgram(list.NewModel())
 		if _, err := p.Run(); err != nil {
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+	"github.com/AtifChy/aiub-notice/internal/toast"
+)
+
+// openCmd represents the open command
+var openCmd = &cobra.Command{
+	Use:   "open <id|position>",
+	Short: "Open a notice in the browser and mark it read",
+	Long: `This command opens the page of a cached notice in the browser and marks the
+notice as read. The notice is chosen as for mark-read. Clicking a toast runs
+this command with the aiub-notice: URI of the notice.
+
+Examples:
+	# open the newest notice
+	aiub-notice open 1
+
+	# open a notice by ID
+	aiub-notice open 3fa9c2e1`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := notice.DefaultStore()
+		if err != nil {
+			return err
+		}
+
+		var id, link string
+		if strings.HasPrefix(args[0], common.URLScheme+":") {
+			if id, link, err = toast.ParseOpenURI(args[0]); err != nil {
+				return err
+			}
+		} else {
+			selected, err := selectCachedNotices(store, args)
+			if err != nil {
+				return err
+			}
+			id, link = selected[0].ID, selected[0].Link
+			if !common.IsWebURL(link) {
+				return fmt.Errorf("notice link is not a web page: %q", link)
+			}
+		}
+
+		// Open the notice even if the read state cannot be saved.
+		if err := store.SetRead(time.Now(), id); err != nil {
+			logger.L().Error("marking notice as read", slog.String("id", id), slog.String("error", err.Error()))
+		}
+		if err := common.OpenURL(link); err != nil {
+			return fmt.Errorf("opening notice: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old notices from the local cache",
+	Long: `This command deletes the notices that fall outside the retention policy from
+the local cache, along with their read state and revisions, and compacts the
+database. Starred notices and notices with a note are kept. The policy is taken
+from the "retention" section of the configuration unless given with flags.
+
+Pruned notices are not announced again if they reappear on the site.
+
+Examples:
+	# show what the configured policy would prune
+	aiub-notice prune --dry-run
+
+	# keep notices from the last year only
+	aiub-notice prune --max-age 365d
+
+	# keep the 500 newest notices
+	aiub-notice prune --max-notices 500`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		policy := cfg.Retention
+		if cmd.Flags().Changed("max-age") {
+			value, _ := cmd.Flags().GetString("max-age")
+			if policy.MaxAge, err = common.ParseDuration(value); err != nil {
+				return fmt.Errorf("parsing max-age flag: %w", err)
+			}
+		}
+		if cmd.Flags().Changed("max-notices") {
+			policy.MaxNotices, _ = cmd.Flags().GetInt("max-notices")
+		}
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		if policy.IsZero() {
+			return errors.New("no retention policy configured; set one in the configuration or use --max-age or --max-notices")
+		}
+
+		store, err := notice.DefaultStore()
+		if err != nil {
+			return err
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			pruned, err := notice.PlanPrune(store, policy, time.Now())
+			if err != nil {
+				return err
+			}
+			if len(pruned) == 0 {
+				logger.L().Info("nothing to prune")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ID\tDATE\tTITLE")
+			for _, n := range pruned {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", n.ID, n.DateText(), n.Title)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			logger.L().Info("would prune notices", slog.Int("count", len(pruned)))
+			return nil
+		}
+
+		pruned, err := notice.Prune(store, policy, time.Now())
+		if err != nil {
+			return err
+		}
+		if len(pruned) == 0 {
+			logger.L().Info("nothing to prune")
+			return nil
+		}
+		if err := store.Compact(); err != nil {
+			return err
+		}
+		logger.L().Info("pruned notices", slog.Int("count", len(pruned)))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().Bool("dry-run", false, "List the notices that would be pruned without deleting them")
+	pruneCmd.Flags().String("max-age", "", `Prune notices older than this, e.g. "365d" (overrides the configuration)`)
+	pruneCmd.Flags().Int("max-notices", 0, "Keep only this many of the newest notices (overrides the configuration)")
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// markReadCmd represents the mark-read command
+var markReadCmd = &cobra.Command{
+	Use:   "mark-read [id|position|range]...",
+	Short: "Mark notices as read",
+	Long: `This command marks cached notices as read. Notices are chosen by ID, which may
+be shortened to any unique prefix, or by position, counting from the newest
+notice as 1 like the last command. Positions can be given as ranges such as
+2-5. A reference made only of digits is a position; write an ID prefix made of
+digits as id:1234. Opening a notice from the list or from a toast marks it read
+as well.
+
+Examples:
+	# mark the newest notice as read
+	aiub-notice mark-read 1
+
+	# mark the five newest notices and one by ID as read
+	aiub-notice mark-read 1-5 3fa9c2e1
+
+	# mark every notice as read
+	aiub-notice mark-read --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setRead(cmd, args, time.Now())
+	},
+}
+
+// markUnreadCmd represents the mark-unread command
+var markUnreadCmd = &cobra.Command{
+	Use:   "mark-unread [id|position|range]...",
+	Short: "Mark notices as unread",
+	Long: `This command marks cached notices as unread again. Notices are chosen the same
+way as for mark-read.
+
+Examples:
+	# mark the newest notice as unread
+	aiub-notice mark-unread 1
+
+	# mark every notice as unread
+	aiub-notice mark-unread --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setRead(cmd, args, time.Time{})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(markReadCmd)
+	rootCmd.AddCommand(markUnreadCmd)
+
+	markReadCmd.Flags().Bool("all", false, "Mark all notices")
+	markUnreadCmd.Flags().Bool("all", false, "Mark all notices")
+}
+
+// setRead marks the notices chosen by args, or all notices with --all, as
+// read at the given time, or as unread if it is zero.
+func setRead(cmd *cobra.Command, args []string, at time.Time) error {
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		return fmt.Errorf("give either notice IDs or positions, or --all")
+	}
+
+	store, err := notice.DefaultStore()
+	if err != nil {
+		return err
+	}
+	var notices []notice.Notice
+	if all {
+		notices, err = store.Notices()
+	} else {
+		notices, err = selectCachedNotices(store, args)
+	}
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(notices))
+	for _, n := range notices {
+		ids = append(ids, n.ID)
+	}
+	if err := store.SetRead(at, ids...); err != nil {
+		return fmt.Errorf("saving read state: %w", err)
+	}
+
+	state := "read"
+	if at.IsZero() {
+		state = "unread"
+	}
+	logger.L().Info("marked notices", slog.Int("count", len(ids)), slog.String("state", state))
+	return nil
+}
+
+// selectCachedNotices returns the cached notices chosen by refs; see
+// selectNotices.
+func selectCachedNotices(store notice.Store, refs []string) ([]notice.Notice, error) {
+	notices, err := store.Notices()
+	if err != nil {
+		return nil, fmt.Errorf("loading cached notices: %w", err)
+	}
+	return selectNotices(notices, refs)
+}
+
+var positionRange = regexp.MustCompile(`^(\d+)(?:-(\d+))?$`)
+
+// idRefPrefix marks a ref as a notice ID prefix, for prefixes made only of
+// digits that would otherwise be read as positions.
+const idRefPrefix = "id:"
+
+// selectNotices returns the notices chosen by refs, in order and without
+// duplicates. A ref is a position or range of positions in notices,
+// counting from 1, or a unique prefix of a notice ID. Refs made only of
+// digits are positions; an ID prefix can be forced with idRefPrefix.
+func selectNotices(notices []notice.Notice, refs []string) ([]notice.Notice, error) {
+	var selected []notice.Notice
+	chosen := make(map[string]struct{})
+	choose := func(n notice.Notice) {
+		if _, ok := chosen[n.ID]; !ok {
+			chosen[n.ID] = struct{}{}
+			selected = append(selected, n)
+		}
+	}
+
+	for _, ref := range refs {
+		prefix, isID := strings.CutPrefix(ref, idRefPrefix)
+		if m := positionRange.FindStringSubmatch(ref); m != nil && !isID {
+			first, _ := strconv.Atoi(m[1])
+			last := first
+			if m[2] != "" {
+				last, _ = strconv.Atoi(m[2])
+			}
+			if first < 1 || last < first || last > len(notices) {
+				if m[2] == "" {
+					return nil, fmt.Errorf("position %s is out of range, there are %d notices; write an ID prefix made of digits as %s%s",
+						ref, len(notices), idRefPrefix, ref)
+				}
+				return nil, fmt.Errorf("positions %s are out of range, there are %d notices", ref, len(notices))
+			}
+			for _, n := range notices[first-1 : last] {
+				choose(n)
+			}
+			continue
+		}
+		if prefix == "" {
+			return nil, fmt.Errorf("empty notice ID in %q", ref)
+		}
+
+		var matches []notice.Notice
+		for _, n := range notices {
+			if strings.HasPrefix(n.ID, prefix) {
+				matches = append(matches, n)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no notice with ID %q", prefix)
+		case 1:
+			choose(matches[0])
+		default:
+			ids := make([]string, 0, len(matches))
+			for _, n := range matches {
+				ids = append(ids, n.ID)
+			}
+			return nil, fmt.Errorf("notice ID %q is ambiguous, it matches %s", prefix, strings.Join(ids, ", "))
+		}
+	}
+	return selected, nil
+}
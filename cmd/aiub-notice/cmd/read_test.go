@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+func Test_selectNotices(t *testing.T) {
+	notices := []notice.Notice{
+		{ID: "3fa9c2e1aa000001", Title: "first"},
+		{ID: "1234abcd00000002", Title: "second"},
+		{ID: "3fa9d00000000003", Title: "third"},
+		{ID: "9b00000000000004", Title: "fourth"},
+	}
+
+	tests := []struct {
+		name    string
+		refs    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "position", refs: []string{"2"}, want: []string{"second"}},
+		{name: "range", refs: []string{"2-4"}, want: []string{"second", "third", "fourth"}},
+		{name: "single position range", refs: []string{"3-3"}, want: []string{"third"}},
+		{name: "ID prefix", refs: []string{"9b"}, want: []string{"fourth"}},
+		{name: "full ID", refs: []string{"3fa9d00000000003"}, want: []string{"third"}},
+		{name: "in order without duplicates", refs: []string{"3", "1-3", "3fa9c"}, want: []string{"third", "first", "second"}},
+		{name: "digits are a position", refs: []string{"1"}, want: []string{"first"}},
+		{name: "digits as ID prefix", refs: []string{"id:1"}, want: []string{"second"}},
+		{name: "digit ID prefix out of range", refs: []string{"1234"}, wantErr: true},
+		{name: "ambiguous ID prefix", refs: []string{"3fa9"}, wantErr: true},
+		{name: "unknown ID", refs: []string{"ffff"}, wantErr: true},
+		{name: "empty ID", refs: []string{"id:"}, wantErr: true},
+		{name: "position zero", refs: []string{"0"}, wantErr: true},
+		{name: "range past the end", refs: []string{"3-5"}, wantErr: true},
+		{name: "reversed range", refs: []string{"3-2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectNotices(notices, tt.refs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectNotices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, n := range selected {
+				got = append(got, n.Title)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectNotices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
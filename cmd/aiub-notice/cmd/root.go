@@ -2,7 +2,11 @@
 package cmd
 
 import (
+	"context"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
@@ -18,6 +22,10 @@ var rootCmd = &cobra.Command{
 	Version: common.Version,
 }
 
+func init() {
+	rootCmd.PersistentFlags().String("config", "", "Path to the configuration file")
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() error {
@@ -31,5 +39,10 @@ func Execute() error {
 		defer func() { _ = logFile.Close() }()
 	}
 
-	return rootCmd.Execute()
+	// Cancel the command context on interrupt so long-running commands can
+	// abort in-flight requests and shut down cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return rootCmd.ExecuteContext(ctx)
 }
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+var (
+	searchMatchStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3"))
+	searchMetaStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#6e738d"))
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the cached notices",
+	Long: `This command searches the title, description and fetched body of the cached
+notices. A notice matches if it contains every word of the query; words in
+double quotes must appear together in that order. Results are ranked by
+relevance, with matches in the title counting most, and shown with an excerpt
+around the first match. The search works offline on the local index.
+
+Examples:
+	# search for notices about the make up exam
+	aiub-notice search '"make up" exam'
+
+	# search news of this year only
+	aiub-notice search convocation --category news --since 2025-01-01`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		q := notice.ParseQuery(strings.Join(args, " "))
+		if q.IsEmpty() {
+			return fmt.Errorf("the query has no words to search for")
+		}
+
+		if err := parseDayFlags(cmd, &q); err != nil {
+			return err
+		}
+		q.Category, _ = cmd.Flags().GetString("category")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		store, err := notice.DefaultStore()
+		if err != nil {
+			return err
+		}
+		results, err := store.Search(q)
+		if err != nil {
+			return fmt.Errorf("searching notices: %w", err)
+		}
+		if len(results) == 0 {
+			logger.L().Info("no matching notices found")
+			return nil
+		}
+
+		mark := func(s string) string { return searchMatchStyle.Render(s) }
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+		for _, r := range results {
+			n := r.Notice
+			fmt.Println(searchMetaStyle.Render(fmt.Sprintf("%s  %s  %s", n.ID, n.DateText(), n.Category)))
+			fmt.Println(notice.HighlightWords(n.Title, q, mark))
+			if r.Snippet != "" && r.Snippet != n.Title {
+				fmt.Println("  " + r.Highlight(mark))
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+// parseDayFlags sets the date range of q from the --since and --until
+// flags. Queries compare calendar days, so the days are not tied to the
+// local time zone.
+func parseDayFlags(cmd *cobra.Command, q *notice.Query) error {
+	for flag, date := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		value, _ := cmd.Flags().GetString(flag)
+		if value == "" {
+			continue
+		}
+		var err error
+		if *date, err = time.Parse(time.DateOnly, value); err != nil {
+			return fmt.Errorf("invalid --%s date %q, expected YYYY-MM-DD", flag, value)
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().String("since", "", "Only notices dated on or after this day (YYYY-MM-DD)")
+	searchCmd.Flags().String("until", "", "Only notices dated on or before this day (YYYY-MM-DD)")
+	searchCmd.Flags().StringP("category", "c", "", "Only notices of this category")
+	searchCmd.Flags().IntP("limit", "n", 20, "Maximum number of results, 0 for all")
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// starCmd represents the star command
+var starCmd = &cobra.Command{
+	Use:   "star [id|position|range]...",
+	Short: "Star notices to find them again",
+	Long: `This command stars cached notices, which can then be listed on their own here
+or in the list view. Notices are chosen as for mark-read. Without arguments it
+lists the starred notices with their notes.
+
+Examples:
+	# star the newest notice
+	aiub-notice star 1
+
+	# list starred notices
+	aiub-notice star`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return listStarred()
+		}
+		return annotate(args, func(a *notice.Annotation) {
+			if !a.Starred() {
+				a.StarredAt = time.Now()
+			}
+		})
+	},
+}
+
+// unstarCmd represents the unstar command
+var unstarCmd = &cobra.Command{
+	Use:   "unstar <id|position|range>...",
+	Short: "Remove the star from notices",
+	Long: `This command removes the star from notices. Their notes are kept.
+
+Examples:
+	# unstar a notice by ID
+	aiub-notice unstar 3fa9c2e1`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return annotate(args, func(a *notice.Annotation) {
+			a.StarredAt = time.Time{}
+		})
+	},
+}
+
+// noteCmd represents the note command
+var noteCmd = &cobra.Command{
+	Use:   "note <id|position> [text]",
+	Short: "Show or set the note of a notice",
+	Long: `This command attaches a free-text note to a notice, replacing any previous
+note. Without text it shows the note of the notice.
+
+Examples:
+	# add a note to the newest notice
+	aiub-notice note 1 "pay the second installment by Friday"
+
+	# show the note
+	aiub-notice note 1
+
+	# remove the note
+	aiub-notice note 1 --clear`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clearNote, _ := cmd.Flags().GetBool("clear")
+		text := strings.TrimSpace(strings.Join(args[1:], " "))
+
+		if !clearNote && text == "" {
+			store, err := notice.DefaultStore()
+			if err != nil {
+				return err
+			}
+			selected, err := selectCachedNotices(store, args[:1])
+			if err != nil {
+				return err
+			}
+			a, err := store.Annotation(selected[0].ID)
+			if err != nil {
+				return fmt.Errorf("loading note: %w", err)
+			}
+			if a.Note == "" {
+				logger.L().Info("the notice has no note")
+				return nil
+			}
+			fmt.Println(a.Note)
+			return nil
+		}
+
+		return annotate(args[:1], func(a *notice.Annotation) {
+			a.Note = text
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(starCmd)
+	rootCmd.AddCommand(unstarCmd)
+	rootCmd.AddCommand(noteCmd)
+
+	noteCmd.Flags().Bool("clear", false, "Remove the note")
+}
+
+// annotate applies edit to the annotations of the notices chosen by refs.
+func annotate(refs []string, edit func(a *notice.Annotation)) error {
+	store, err := notice.DefaultStore()
+	if err != nil {
+		return err
+	}
+	selected, err := selectCachedNotices(store, refs)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range selected {
+		a, err := store.Annotation(n.ID)
+		if err != nil {
+			return fmt.Errorf("loading annotation: %w", err)
+		}
+		edit(&a)
+		a.UpdatedAt = time.Now()
+		if err := store.SetAnnotation(n.ID, a); err != nil {
+			return fmt.Errorf("saving annotation: %w", err)
+		}
+	}
+	return nil
+}
+
+func listStarred() error {
+	store, err := notice.DefaultStore()
+	if err != nil {
+		return err
+	}
+	annotations, err := store.Annotations()
+	if err != nil {
+		return fmt.Errorf("loading annotations: %w", err)
+	}
+	notices, err := store.Notices()
+	if err != nil {
+		return fmt.Errorf("loading cached notices: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tDATE\tTITLE\tNOTE")
+	count := 0
+	for _, n := range notices {
+		a := annotations[n.ID]
+		if !a.Starred() {
+			continue
+		}
+		count++
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", n.ID, n.DateText(), n.Title, a.Note)
+	}
+	if count == 0 {
+		logger.L().Info("no starred notices")
+		return nil
+	}
+	return w.Flush()
+}
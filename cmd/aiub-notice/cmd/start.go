@@ -42,7 +42,15 @@ func run(cmd *cobra.Command, args []string) {
 		logger.L().Error("parsing interval flag", slog.String("error", err.Error()))
 		return
 	}
-	service.Run(checkInterval)
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		logger.L().Error("loading configuration", slog.String("error", err.Error()))
+		return
+	}
+	if err := service.Run(cmd.Context(), cfg, checkInterval); err != nil {
+		logger.L().Error("running service", slog.String("error", err.Error()))
+		return
+	}
 
 	logger.L().Info("service stopped.")
 }
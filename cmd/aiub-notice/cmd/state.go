@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// exportStateCmd represents the export-state command
+var exportStateCmd = &cobra.Command{
+	Use:   "export-state",
+	Short: "Export the local state to move or merge it elsewhere",
+	Long: `This command writes everything known about notices to a JSON file: the cached
+notices, which were seen and read, their revisions, stars and notes. Import
+the file on another machine with import-state.
+
+Examples:
+	# export the state to a file
+	aiub-notice export-state -o laptop.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := notice.DefaultStore()
+		if err != nil {
+			return err
+		}
+		state, err := store.ExportState()
+		if err != nil {
+			return fmt.Errorf("exporting state: %w", err)
+		}
+		state.Machine, _ = os.Hostname()
+		state.ExportedAt = time.Now()
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			return notice.WriteState(os.Stdout, state)
+		}
+		var buf bytes.Buffer
+		if err := notice.WriteState(&buf, state); err != nil {
+			return err
+		}
+		if err := common.WriteFileAtomic(output, buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("writing state: %w", err)
+		}
+		logger.L().Info("exported state",
+			slog.Int("notices", len(state.Notices)),
+			slog.String("file", output),
+		)
+		return nil
+	},
+}
+
+// importStateCmd represents the import-state command
+var importStateCmd = &cobra.Command{
+	Use:   "import-state <file>",
+	Short: "Import state exported by export-state",
+	Long: `This command imports a file written by export-state. By default it replaces
+the local state, after backing it up; with --merge the file is merged into the
+local state instead. Merging never loses data: seen and read notices and
+revisions are united, and the latest version of each star and note is kept.
+
+Examples:
+	# combine the state of the laptop with this machine's
+	aiub-notice import-state --merge laptop.json
+
+	# set up a new machine from an export
+	aiub-notice import-state laptop.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("opening state file: %w", err)
+		}
+		state, err := notice.ReadState(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+
+		store, err := notice.DefaultStore()
+		if err != nil {
+			return err
+		}
+		merge, _ := cmd.Flags().GetBool("merge")
+		if !merge {
+			if err := store.Backup(); err != nil {
+				return fmt.Errorf("backing up store before replacing it: %w", err)
+			}
+		}
+		stats, err := store.ImportState(state, !merge)
+		if err != nil {
+			return fmt.Errorf("importing state: %w", err)
+		}
+		logImportStats("imported state", stats)
+		return nil
+	},
+}
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync [folder]",
+	Short: "Merge state with other machines through a shared folder",
+	Long: `This command merges the state files of the other machines in a folder shared
+between them, such as a Syncthing folder, and writes this machine's state file
+there. Each machine writes only its own file, so the folder never has
+conflicting changes. The folder defaults to "sync_dir" from the configuration;
+the running service syncs through it before and after every check.
+
+Examples:
+	# sync through the configured folder
+	aiub-notice sync
+
+	# sync through another folder
+	aiub-notice sync ~/Sync/aiub-notice`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		dir := cfg.SyncDir
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		if dir == "" {
+			return errors.New("no sync folder given; pass one or set sync_dir in the configuration")
+		}
+
+		store, err := notice.DefaultStore()
+		if err != nil {
+			return err
+		}
+		syncer := notice.NewSyncer(store, dir)
+		stats, pullErr := syncer.Pull()
+		logImportStats("merged synced state", stats)
+		if err := syncer.Push(); err != nil {
+			return errors.Join(pullErr, err)
+		}
+		return pullErr
+	},
+}
+
+func logImportStats(msg string, stats notice.ImportStats) {
+	logger.L().Info(msg,
+		slog.Int("notices", stats.Notices),
+		slog.Int("seen", stats.Seen),
+		slog.Int("read", stats.Read),
+		slog.Int("revisions", stats.Revisions),
+		slog.Int("annotations", stats.Annotations),
+	)
+}
+
+func init() {
+	rootCmd.AddCommand(exportStateCmd)
+	rootCmd.AddCommand(importStateCmd)
+	rootCmd.AddCommand(syncCmd)
+	exportStateCmd.Flags().StringP("output", "o", "", "Write to this file instead of standard output")
+	importStateCmd.Flags().Bool("merge", false, "Merge the file into the local state instead of replacing it")
+}
@@ -12,8 +12,10 @@ require (
 	github.com/evertras/bubble-table v0.19.2
 	github.com/fatih/color v1.18.0
 	github.com/jxeng/shortcut v1.0.2
-	github.com/spf13/cobra v1.10.1
-	golang.org/x/sys v0.36.0
+	github.com/spf13/cobra v1.10.2
+	go.etcd.io/bbolt v1.5.0
+	golang.org/x/net v0.44.0
+	golang.org/x/sys v0.45.0
 	golang.org/x/term v0.35.0
 )
 
@@ -42,6 +44,5 @@ require (
 	github.com/spf13/pflag v1.0.10 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	golang.org/x/exp v0.0.0-20231006140011-7918f672742d // indirect
-	golang.org/x/net v0.44.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
 )
@@ -0,0 +1,62 @@
+package appid
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const classesRoot = `SOFTWARE\Classes`
+
+// RegisterProtocol registers a URL protocol for the current user, so that
+// opening scheme:... URIs runs command with the open subcommand and the URI.
+func RegisterProtocol(scheme, displayName, command string) error {
+	if !filepath.IsAbs(command) {
+		return fmt.Errorf("command must be an absolute path: %s", command)
+	}
+
+	regPath := classesRoot + `\` + scheme
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, regPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("create registry key %s: %w", regPath, err)
+	}
+	defer func() { _ = key.Close() }()
+
+	if err := key.SetStringValue("", "URL:"+displayName); err != nil {
+		return fmt.Errorf("set description of %s: %w", scheme, err)
+	}
+	if err := key.SetStringValue("URL Protocol", ""); err != nil {
+		return fmt.Errorf("mark %s as URL protocol: %w", scheme, err)
+	}
+
+	cmdPath := regPath + `\shell\open\command`
+	cmdKey, _, err := registry.CreateKey(registry.CURRENT_USER, cmdPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("create registry key %s: %w", cmdPath, err)
+	}
+	defer func() { _ = cmdKey.Close() }()
+
+	if err := cmdKey.SetStringValue("", fmt.Sprintf(`"%s" open "%%1"`, command)); err != nil {
+		return fmt.Errorf("set open command of %s: %w", scheme, err)
+	}
+	return nil
+}
+
+// UnregisterProtocol removes a URL protocol registered by RegisterProtocol.
+func UnregisterProtocol(scheme string) error {
+	// registry.DeleteKey does not delete subkeys
+	regPath := classesRoot + `\` + scheme
+	for _, path := range []string{
+		regPath + `\shell\open\command`,
+		regPath + `\shell\open`,
+		regPath + `\shell`,
+		regPath,
+	} {
+		if err := registry.DeleteKey(registry.CURRENT_USER, path); err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return fmt.Errorf("delete registry key %s: %w", path, err)
+		}
+	}
+	return nil
+}
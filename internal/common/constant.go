@@ -6,6 +6,9 @@ const (
 	LauncherName = AppName + "-launcher"
 	AppID        = "org.atifchy." + AppName
 	DisplayName  = "AIUB Notice"
+	// URLScheme is the URL protocol through which toasts open notices, so
+	// that opening one also marks it as read.
+	URLScheme = AppName
 )
 
 var Version = "dev"
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "90s", want: 90 * time.Second},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: "0d", want: 0},
+		{in: "1.5d", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDuration(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuration(%q) error = %v, wantErr %t", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.D() != tt.want {
+				t.Errorf("ParseDuration(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
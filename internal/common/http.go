@@ -0,0 +1,197 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Environment variables overriding the HTTP settings of the configuration
+// file. The standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables are
+// honoured as well when no proxy is configured.
+const (
+	EnvProxy     = "AIUB_NOTICE_PROXY"
+	EnvCABundle  = "AIUB_NOTICE_CA_BUNDLE"
+	EnvUserAgent = "AIUB_NOTICE_USER_AGENT"
+	EnvTimeout   = "AIUB_NOTICE_TIMEOUT"
+)
+
+// HTTPConfig describes the HTTP client shared by every network call.
+type HTTPConfig struct {
+	// Proxy is the URL of the proxy to use for all requests. When empty the
+	// proxy is taken from the environment.
+	Proxy string `json:"proxy,omitempty"`
+	// CABundle is a PEM file with certificates trusted in addition to the
+	// system roots, e.g. for a TLS-intercepting firewall.
+	CABundle string `json:"ca_bundle,omitempty"`
+	// UserAgent is sent with every request.
+	UserAgent string `json:"user_agent,omitempty"`
+	// Timeout limits a whole request, including reading the body.
+	Timeout Duration `json:"timeout"`
+	// ConnectTimeout limits establishing the connection and TLS handshake.
+	ConnectTimeout Duration `json:"connect_timeout"`
+}
+
+// DefaultHTTPConfig returns the HTTP settings used unless configured
+// otherwise.
+func DefaultHTTPConfig() HTTPConfig {
+	return HTTPConfig{
+		UserAgent:      AppName + "/" + Version,
+		Timeout:        Duration(30 * time.Second),
+		ConnectTimeout: Duration(10 * time.Second),
+	}
+}
+
+// WithDefaults returns a copy of c with unset fields taken from
+// DefaultHTTPConfig.
+func (c HTTPConfig) WithDefaults() HTTPConfig {
+	d := DefaultHTTPConfig()
+	if c.UserAgent == "" {
+		c.UserAgent = d.UserAgent
+	}
+	if c.Timeout == 0 {
+		c.Timeout = d.Timeout
+	}
+	if c.ConnectTimeout == 0 {
+		c.ConnectTimeout = d.ConnectTimeout
+	}
+	return c
+}
+
+// WithEnv returns a copy of c with the settings given in the environment
+// applied over it.
+func (c HTTPConfig) WithEnv() (HTTPConfig, error) {
+	if v := os.Getenv(EnvProxy); v != "" {
+		c.Proxy = v
+	}
+	if v := os.Getenv(EnvCABundle); v != "" {
+		c.CABundle = v
+	}
+	if v := os.Getenv(EnvUserAgent); v != "" {
+		c.UserAgent = v
+	}
+	if v := os.Getenv(EnvTimeout); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return c, fmt.Errorf("%s: %w", EnvTimeout, err)
+		}
+		c.Timeout = Duration(timeout)
+	}
+	return c, nil
+}
+
+// Validate reports whether the settings are usable.
+func (c HTTPConfig) Validate() error {
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("http: invalid proxy %q", c.Proxy)
+		}
+	}
+	if c.Timeout < 0 || c.ConnectTimeout < 0 {
+		return fmt.Errorf("http: timeouts must not be negative")
+	}
+	return nil
+}
+
+// NewHTTPClient creates a client from c. Unset fields use the defaults.
+func NewHTTPClient(c HTTPConfig) (*http.Client, error) {
+	c = c.WithDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   c.ConnectTimeout.D(),
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = c.ConnectTimeout.D()
+
+	if c.Proxy != "" {
+		proxy, _ := url.Parse(c.Proxy)
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if c.CABundle != "" {
+		pem, err := os.ReadFile(c.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no certificates", c.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{
+		Timeout:   c.Timeout.D(),
+		Transport: &userAgentTransport{base: transport, userAgent: c.UserAgent},
+	}, nil
+}
+
+// userAgentTransport sets the User-Agent of requests that do not have one.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Header.Get("User-Agent") == "" {
+		r = r.Clone(r.Context())
+		r.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(r)
+}
+
+var (
+	httpMu     sync.Mutex
+	httpClient *http.Client
+)
+
+// SetHTTPConfig replaces the shared client with one built from c, with the
+// environment overrides applied.
+func SetHTTPConfig(c HTTPConfig) error {
+	c, err := c.WithEnv()
+	if err != nil {
+		return err
+	}
+	client, err := NewHTTPClient(c)
+	if err != nil {
+		return err
+	}
+
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	httpClient = client
+	return nil
+}
+
+// HTTPClient returns the shared client. Until SetHTTPConfig is called it
+// uses the default settings and the environment overrides; invalid
+// overrides are ignored here and reported by SetHTTPConfig.
+func HTTPClient() *http.Client {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+
+	if httpClient == nil {
+		c, err := DefaultHTTPConfig().WithEnv()
+		if err == nil {
+			httpClient, err = NewHTTPClient(c)
+		}
+		if err != nil {
+			httpClient, _ = NewHTTPClient(DefaultHTTPConfig())
+		}
+	}
+	return httpClient
+}
@@ -0,0 +1,111 @@
+package common
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	var gotAgent, gotURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAgent = r.UserAgent()
+		gotURL = r.URL.String()
+	}))
+	defer server.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o644); err != nil {
+		t.Fatalf("write CA bundle: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  HTTPConfig
+		url     string
+		wantErr bool
+		check   func(t *testing.T)
+	}{
+		{
+			name:   "default user agent",
+			config: HTTPConfig{},
+			url:    server.URL + "/page",
+			check: func(t *testing.T) {
+				if gotAgent != AppName+"/"+Version {
+					t.Errorf("user agent = %q", gotAgent)
+				}
+			},
+		},
+		{
+			name:   "proxy receives absolute URL",
+			config: HTTPConfig{Proxy: server.URL, UserAgent: "campus-bot"},
+			url:    "http://notices.invalid/list",
+			check: func(t *testing.T) {
+				if gotURL != "http://notices.invalid/list" || gotAgent != "campus-bot" {
+					t.Errorf("proxy got url %q, agent %q", gotURL, gotAgent)
+				}
+			},
+		},
+		{
+			name:   "CA bundle is trusted",
+			config: HTTPConfig{CABundle: bundle},
+			url:    tlsServer.URL,
+		},
+		{
+			name:    "untrusted certificate fails",
+			config:  HTTPConfig{},
+			url:     tlsServer.URL,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewHTTPClient(tt.config)
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			response, err := client.Get(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					_ = response.Body.Close()
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			_ = response.Body.Close()
+			if tt.check != nil {
+				tt.check(t)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig_WithEnv(t *testing.T) {
+	t.Setenv(EnvUserAgent, "env-agent")
+	t.Setenv(EnvTimeout, "1m")
+
+	c, err := HTTPConfig{UserAgent: "file-agent", Proxy: "http://proxy:3128"}.WithEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.UserAgent != "env-agent" || c.Timeout.D() != time.Minute || c.Proxy != "http://proxy:3128" {
+		t.Errorf("unexpected config: %+v", c)
+	}
+
+	t.Setenv(EnvTimeout, "long")
+	if _, err := (HTTPConfig{}).WithEnv(); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+}
@@ -11,7 +11,7 @@ import (
 const iconURL = "https://www.aiub.edu/Files/Templates/AIUBv3/assets/images/aiub-logo-white-border.svg"
 
 func fetchIcon(url, dest string) error {
-	response, err := http.Get(url)
+	response, err := HTTPClient().Get(url)
 	if err != nil {
 		return fmt.Errorf("download icon: %w", err)
 	}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"net/url"
+	"os/exec"
+	"runtime"
+)
+
+// IsWebURL reports whether link is an absolute http or https URL. Links
+// taken from the site must pass it before they are opened, so that they
+// cannot launch local files or other protocol handlers.
+func IsWebURL(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// OpenURL opens a URL or file path with the system's default handler.
+func OpenURL(url string) error {
+	var cmd string
+	var args []string
+
+	switch runtime.GOOS {
+	case "windows":
+		cmd = "rundll32"
+		args = []string{"url.dll,FileProtocolHandler", url}
+	case "darwin":
+		cmd = "open"
+		args = []string{url}
+	default: // "linux", "freebsd", "openbsd", "netbsd"
+		cmd = "xdg-open"
+		args = []string{url}
+	}
+
+	return exec.Command(cmd, args...).Start()
+}
@@ -0,0 +1,27 @@
+package common
+
+import "testing"
+
+func Test_IsWebURL(t *testing.T) {
+	tests := []struct {
+		link string
+		want bool
+	}{
+		{link: "https://www.aiub.edu/Files/Uploads/routine.pdf", want: true},
+		{link: "HTTP://www.aiub.edu/notice", want: true},
+		{link: "file:///C:/Windows/System32/calc.exe"},
+		{link: "javascript:alert(1)"},
+		{link: "ms-settings:privacy"},
+		{link: "https:relative"},
+		{link: "/Files/Uploads/routine.pdf"},
+		{link: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			if got := IsWebURL(tt.link); got != tt.want {
+				t.Errorf("IsWebURL(%q) = %v, want %v", tt.link, got, tt.want)
+			}
+		})
+	}
+}
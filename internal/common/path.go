@@ -15,6 +15,15 @@ func GetDataPath() (string, error) {
 	return filepath.Join(cacheDir, AppName), nil
 }
 
+// GetConfigPath returns the path to the application's configuration file.
+func GetConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, AppName, "config.json"), nil
+}
+
 var GetLogPath = func() string {
 	return filepath.Join(os.TempDir(), AppName+".log")
 }
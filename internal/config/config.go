@@ -0,0 +1,176 @@
+// Package config loads the user configuration of the application.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// Config is the user configuration, read from a JSON file. Missing settings
+// fall back to the values returned by Default.
+type Config struct {
+	// Sources are the notice pages to watch. Entries named after a built-in
+	// source override it; other names add a new source. Each entry only
+	// needs the fields that differ from the source it is based on.
+	Sources []notice.SourceConfig `json:"sources"`
+
+	// Retry controls how failed requests are retried. Unset fields keep
+	// their default values.
+	Retry notice.RetryPolicy `json:"retry"`
+
+	// HTTP configures the client used for every request. The
+	// AIUB_NOTICE_* environment variables take precedence over it.
+	HTTP common.HTTPConfig `json:"http"`
+
+	// Retention bounds how many notices are kept. By default every notice
+	// is kept.
+	Retention notice.RetentionPolicy `json:"retention"`
+
+	// SyncDir is a folder shared with other machines, such as a Syncthing
+	// folder, through which the service merges their seen and read notices,
+	// history, stars and notes with its own. Empty disables syncing.
+	SyncDir string `json:"sync_dir,omitempty"`
+
+	// NotifyWithdrawn enables a notification when a known notice is taken
+	// down from the site.
+	NotifyWithdrawn bool `json:"notify_withdrawn"`
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Sources: notice.BuiltinSources(),
+		Retry:   notice.DefaultRetryPolicy,
+		HTTP:    common.DefaultHTTPConfig(),
+	}
+}
+
+// Path returns the configuration file path, honouring override if set.
+func Path(override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	return common.GetConfigPath()
+}
+
+// Load reads the configuration file at path. A missing file is not an error
+// and yields the default configuration.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	cfg.Sources = nil
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// normalize merges the configured sources over the built-in ones, fills
+// unset fields with defaults and validates the result.
+func (c *Config) normalize() error {
+	sources := notice.BuiltinSources()
+	index := make(map[string]int, len(sources))
+	for i, src := range sources {
+		index[src.Name] = i
+	}
+
+	configured := make(map[string]struct{}, len(c.Sources))
+	for _, src := range c.Sources {
+		src = src.WithDefaults()
+		if err := src.Validate(); err != nil {
+			return err
+		}
+		if _, dup := configured[src.Name]; dup {
+			return fmt.Errorf("duplicate source name %q", src.Name)
+		}
+		configured[src.Name] = struct{}{}
+
+		if i, ok := index[src.Name]; ok {
+			sources[i] = src
+		} else {
+			sources = append(sources, src)
+		}
+	}
+	c.Sources = sources
+
+	c.Retry = c.Retry.WithDefaults()
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Retention.Validate(); err != nil {
+		return err
+	}
+
+	c.HTTP = c.HTTP.WithDefaults()
+	if err := c.HTTP.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EnabledSources returns the sources that should be fetched.
+func (c *Config) EnabledSources() []notice.SourceConfig {
+	var sources []notice.SourceConfig
+	for _, src := range c.Sources {
+		if src.IsEnabled() {
+			sources = append(sources, src)
+		}
+	}
+	return sources
+}
+
+// Source returns the source with the given name.
+func (c *Config) Source(name string) (notice.SourceConfig, error) {
+	for _, src := range c.Sources {
+		if src.Name == name {
+			return src, nil
+		}
+	}
+	return notice.SourceConfig{}, fmt.Errorf("unknown source %q", name)
+}
+
+// Encode writes the configuration to w as indented JSON.
+func (c *Config) Encode(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
+
+// Write stores the configuration at path, creating the parent directory if
+// needed. The file is replaced atomically.
+func (c *Config) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Encode(&buf); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := common.WriteFileAtomic(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+	return nil
+}
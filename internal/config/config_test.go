@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+func Test_Load(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "missing file yields defaults",
+			check: func(t *testing.T, cfg *Config) {
+				enabled := cfg.EnabledSources()
+				if len(enabled) != 1 || enabled[0].Name != notice.DefaultSource.Name {
+					t.Errorf("expected only the default source enabled, got %+v", enabled)
+				}
+			},
+		},
+		{
+			name:    "new source is filled from defaults",
+			content: `{"sources": [{"name": "mirror", "base_url": "http://localhost:8080"}]}`,
+			check: func(t *testing.T, cfg *Config) {
+				src, err := cfg.Source("mirror")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if src.BaseURL != "http://localhost:8080" || !src.IsEnabled() {
+					t.Errorf("overrides lost: %+v", src)
+				}
+				if src.Selectors.Item != notice.DefaultSource.Selectors.Item || len(src.DateLayouts) == 0 {
+					t.Errorf("defaults not applied: %+v", src)
+				}
+				if len(cfg.EnabledSources()) != 2 {
+					t.Errorf("expected mirror next to the default source, got %+v", cfg.EnabledSources())
+				}
+			},
+		},
+		{
+			name:    "built-in category can be enabled and default disabled",
+			content: `{"sources": [{"name": "news", "enabled": true}, {"name": "notices", "enabled": false}]}`,
+			check: func(t *testing.T, cfg *Config) {
+				enabled := cfg.EnabledSources()
+				if len(enabled) != 1 || enabled[0].Name != "news" {
+					t.Fatalf("expected only news enabled, got %+v", enabled)
+				}
+				if enabled[0].Category != "News" || enabled[0].ListPath != "/category/news" {
+					t.Errorf("built-in values lost: %+v", enabled[0])
+				}
+			},
+		},
+		{
+			name:    "invalid base url",
+			content: `{"sources": [{"base_url": "ftp://example.com"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate source names",
+			content: `{"sources": [{"name": "a"}, {"name": "a"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "partial retry policy keeps defaults",
+			content: `{"retry": {"max_attempts": 2, "budget": "10s"}}`,
+			check: func(t *testing.T, cfg *Config) {
+				want := notice.DefaultRetryPolicy
+				want.MaxAttempts = 2
+				want.Budget = common.Duration(10 * time.Second)
+				if cfg.Retry != want {
+					t.Errorf("retry = %+v, want %+v", cfg.Retry, want)
+				}
+			},
+		},
+		{
+			name:    "invalid retry delay",
+			content: `{"retry": {"base_delay": "soon"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "retention in days",
+			content: `{"retention": {"max_age": "365d"}}`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Retention.MaxAge.D() != 365*24*time.Hour || cfg.Retention.MaxNotices != 0 {
+					t.Errorf("retention = %+v", cfg.Retention)
+				}
+			},
+		},
+		{
+			name:    "negative retention",
+			content: `{"retention": {"max_notices": -1}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatalf("write config: %v", err)
+				}
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", cfg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
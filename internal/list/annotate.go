@@ -0,0 +1,76 @@
+package list
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/evertras/bubble-table/table"
+
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// annotate applies edit to the annotations of the notices with the given
+// IDs and reloads the rows.
+func (m Model) annotate(ids []string, edit func(a *notice.Annotation)) Model {
+	store, err := notice.DefaultStore()
+	if err != nil {
+		fmt.Println("Error opening the notice store:", err)
+		return m
+	}
+	for _, id := range ids {
+		a, err := store.Annotation(id)
+		if err == nil {
+			edit(&a)
+			a.UpdatedAt = time.Now()
+			err = store.SetAnnotation(id, a)
+		}
+		if err != nil {
+			fmt.Println("Error saving annotation:", err)
+			return m
+		}
+	}
+	return m.reload()
+}
+
+// toggleStar stars the notices of rows, or unstars them if the first one is
+// already starred.
+func (m Model) toggleStar(rows []table.Row) Model {
+	starred, _ := rows[0].Data[columnKeyStarred].(bool)
+	var ids []string
+	for _, row := range rows {
+		if id, ok := row.Data[columnKeyID].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	now := time.Now()
+	return m.annotate(ids, func(a *notice.Annotation) {
+		switch {
+		case starred:
+			a.StarredAt = time.Time{}
+		case !a.Starred():
+			a.StarredAt = now
+		}
+	})
+}
+
+// editNote starts editing the note of the notice of row.
+func (m Model) editNote(row table.Row) (Model, tea.Cmd) {
+	id, ok := row.Data[columnKeyID].(string)
+	if !ok {
+		return m, nil
+	}
+	store, err := notice.DefaultStore()
+	if err != nil {
+		fmt.Println("Error opening the notice store:", err)
+		return m, nil
+	}
+	a, err := store.Annotation(id)
+	if err != nil {
+		fmt.Println("Error loading note:", err)
+		return m, nil
+	}
+
+	m.noteID = id
+	return m.openPrompt(promptNote, a.Note)
+}
@@ -7,9 +7,14 @@ import (
 
 type KeyMap struct {
 	table.KeyMap
-	RowOpen key.Binding
-	Help    key.Binding
-	Quit    key.Binding
+	RowOpen     key.Binding
+	ToggleRead  key.Binding
+	ToggleStar  key.Binding
+	EditNote    key.Binding
+	StarredOnly key.Binding
+	Search      key.Binding
+	Help        key.Binding
+	Quit        key.Binding
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
@@ -19,7 +24,9 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.RowDown, k.RowUp},
-		{k.RowSelectToggle, k.RowOpen},
+		{k.RowSelectToggle, k.RowOpen, k.ToggleRead},
+		{k.ToggleStar, k.EditNote, k.StarredOnly},
+		{k.Search},
 		{k.Filter, k.FilterBlur, k.FilterClear},
 		{k.Help, k.Quit},
 	}
@@ -37,6 +44,26 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "open"),
 		),
+		ToggleRead: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mark read/unread"),
+		),
+		ToggleStar: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "star/unstar"),
+		),
+		EditNote: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "edit note"),
+		),
+		StarredOnly: key.NewBinding(
+			key.WithKeys("*"),
+			key.WithHelp("*", "starred only"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "search"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "more"),
@@ -1,29 +1,49 @@
-// Package list provides a table model for displaying a list of items with title and date columns.
+// Package list provides a table model for displaying a list of items with title, category and date columns.
 package list
 
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/evertras/bubble-table/table"
 
+	"github.com/AtifChy/aiub-notice/internal/common"
 	"github.com/AtifChy/aiub-notice/internal/notice"
 )
 
 const (
-	columnKeyTitle = "title"
-	columnKeyDate  = "date"
-	columnKeyLink  = "link"
+	columnKeyTitle    = "title"
+	columnKeyCategory = "category"
+	columnKeyDate     = "date"
+	columnKeyLink     = "link"
+	columnKeyPreview  = "preview"
+	columnKeyID       = "id"
+	columnKeyRead     = "read"
+	columnKeyStarred  = "starred"
 )
 
+// previewLines is the number of body lines shown below the table.
+const previewLines = 4
+
 var (
 	baseStyle      = lipgloss.NewStyle().BorderForeground(lipgloss.Color("#494d64"))
 	headerStyle    = lipgloss.NewStyle().Align(lipgloss.Center).Bold(true).Foreground(lipgloss.Color("6"))
 	highlightStyle = lipgloss.NewStyle().Background(lipgloss.Color("#363a4f"))
+	withdrawnStyle = lipgloss.NewStyle().Strikethrough(true).Foreground(lipgloss.Color("#6e738d"))
+	titleStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6")).PaddingLeft(1)
+	counterStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#a5adcb"))
+	matchStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3"))
+	previewStyle   = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#a5adcb")).
+			Border(lipgloss.NormalBorder(), true, false, false, false).
+			BorderForeground(lipgloss.Color("#494d64")).
+			PaddingLeft(1)
 )
 
 var (
@@ -33,10 +53,23 @@ var (
 )
 
 type Model struct {
-	table table.Model
-	keys  KeyMap
-	help  help.Model
-	width int
+	table        table.Model
+	keys         KeyMap
+	help         help.Model
+	width        int
+	previewWidth int
+	unread       int
+
+	// starredOnly limits the table to starred notices.
+	starredOnly bool
+	// query limits the table to the notices matching it, best first.
+	query string
+
+	// prompt reads a note for noteID or a search query, as set by
+	// prompting.
+	prompt    textinput.Model
+	prompting promptKind
+	noteID    string
 }
 
 func NewModel() Model {
@@ -44,10 +77,13 @@ func NewModel() Model {
 		table.NewFlexColumn(columnKeyTitle, "Title", 6).
 			WithStyle(lipgloss.NewStyle().Align(lipgloss.Left)).
 			WithFiltered(true),
+		table.NewFlexColumn(columnKeyCategory, "Category", 1).
+			WithStyle(lipgloss.NewStyle().Align(lipgloss.Center)).
+			WithFiltered(true),
 		table.NewFlexColumn(columnKeyDate, "Date", 2).
 			WithStyle(lipgloss.NewStyle().Align(lipgloss.Center)),
 	}
-	rows := getRows()
+	rows, unread := getRows(false, "")
 
 	km := DefaultKeyMap()
 
@@ -74,41 +110,181 @@ func NewModel() Model {
 			HeaderStyle(headerStyle).
 			HighlightStyle(highlightStyle).
 			WithRows(rows),
-		help:  hm,
-		keys:  km,
-		width: 90,
+		help:         hm,
+		keys:         km,
+		width:        90,
+		previewWidth: 90,
+		unread:       unread,
+		prompt:       newPrompt(),
 	}
 }
 
-func getRows() []table.Row {
-	notices, err := notice.GetCachedNotices()
+// getRows returns the table rows of the cached notices, or only of the
+// starred ones or of those matching query, and the number of unread
+// notices.
+func getRows(starredOnly bool, query string) ([]table.Row, int) {
+	store, err := notice.DefaultStore()
+	if err != nil {
+		fmt.Println("Error opening the notice store:", err)
+		return []table.Row{}, 0
+	}
+	notices, err := store.Notices()
 	if err != nil {
 		fmt.Println("Error loading notices from cache:", err)
-		return []table.Row{}
+		return []table.Row{}, 0
+	}
+	read, err := store.Read()
+	if err != nil {
+		fmt.Println("Error loading read notices:", err)
+	}
+	annotations, err := store.Annotations()
+	if err != nil {
+		fmt.Println("Error loading starred notices:", err)
 	}
 
-	var rows []table.Row
+	unread := 0
+	for _, n := range notices {
+		if _, isRead := read[n.ID]; !isRead {
+			unread++
+		}
+	}
+
+	matches := make(map[string]string)
+	if query != "" {
+		results, err := store.Search(notice.ParseQuery(query))
+		if err != nil {
+			fmt.Println("Error searching notices:", err)
+		}
+		notices = notices[:0]
+		for _, r := range results {
+			notices = append(notices, r.Notice)
+			matches[r.Notice.ID] = r.Highlight(func(s string) string { return matchStyle.Render(s) })
+		}
+	}
 
+	var rows []table.Row
 	for _, n := range notices {
+		_, isRead := read[n.ID]
+		a := annotations[n.ID]
+		if starredOnly && !a.Starred() {
+			continue
+		}
+
+		title := n.Title
+		if a.Starred() {
+			title = "★ " + title
+		}
 		row := table.NewRow(table.RowData{
-			columnKeyTitle: n.Title,
-			columnKeyDate:  n.Date.Format("02 Jan 2006"),
-			columnKeyLink:  n.Link,
+			columnKeyTitle:    title,
+			columnKeyCategory: n.Category,
+			columnKeyDate:     n.DateText(),
+			columnKeyLink:     n.Link,
+			columnKeyPreview:  previewText(n, a, matches[n.ID]),
+			columnKeyID:       n.ID,
+			columnKeyRead:     isRead,
+			columnKeyStarred:  a.Starred(),
 		})
 
-		for word, style := range keywordStyles {
+		style := lipgloss.NewStyle()
+		for word, keywordStyle := range keywordStyles {
 			if strings.Contains(strings.ToLower(n.Title), word) {
-				row = row.WithStyle(style)
+				style = keywordStyle
 				break
 			}
 		}
+		if !isRead {
+			style = style.Bold(true)
+		}
+		if n.Withdrawn() {
+			style = withdrawnStyle
+		}
+		row = row.WithStyle(style)
 
 		rows = append(rows, row)
 	}
 
+	return rows, unread
+}
+
+// setRead marks the notices of rows as read now, or as unread, and reloads
+// the rows to show their new state.
+func (m Model) setRead(rows []table.Row, read bool) Model {
+	var ids []string
+	for _, row := range rows {
+		if id, ok := row.Data[columnKeyID].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	store, err := notice.DefaultStore()
+	if err == nil {
+		at := time.Time{}
+		if read {
+			at = time.Now()
+		}
+		err = store.SetRead(at, ids...)
+	}
+	if err != nil {
+		fmt.Println("Error saving read state:", err)
+		return m
+	}
+
+	return m.reload()
+}
+
+// reload reads the rows from the store again.
+func (m Model) reload() Model {
+	var rows []table.Row
+	rows, m.unread = getRows(m.starredOnly, m.query)
+	m.table = m.table.WithRows(rows)
+	return m
+}
+
+// targetRows returns the selected rows, or the highlighted row if none is
+// selected.
+func (m Model) targetRows() []table.Row {
+	rows := m.table.SelectedRows()
+	if len(rows) == 0 {
+		rows = []table.Row{m.table.HighlightedRow()}
+	}
 	return rows
 }
 
+// previewText returns the text shown in the preview pane for a notice,
+// preferring the full body, or the excerpt matching the search, over the
+// listing description, below the note attached to it.
+func previewText(n notice.Notice, a notice.Annotation, match string) string {
+	text := n.Desc
+	if n.Body != "" {
+		text = n.Body
+	}
+	if match != "" {
+		text = match
+	}
+	if a.Note != "" {
+		text = "Note: " + a.Note + "\n" + text
+	}
+
+	var meta []string
+	meta = append(meta, n.ID)
+	if n.Withdrawn() {
+		meta = append(meta, "Withdrawn "+n.WithdrawnAt.Format("02 Jan 2006"))
+	}
+	if n.Author != "" {
+		meta = append(meta, n.Author)
+	}
+	if !n.Posted.IsZero() {
+		meta = append(meta, n.Posted.Format("02 Jan 2006 03:04 PM"))
+	}
+	if !n.LastSeen.IsZero() {
+		meta = append(meta, "seen "+n.FirstSeen.Format("02 Jan 2006")+" – "+n.LastSeen.Format("02 Jan 2006"))
+	}
+	if len(meta) > 0 {
+		text = strings.Join(meta, " · ") + "\n" + text
+	}
+	return text
+}
+
 var keywordStyles = map[string]lipgloss.Style{
 	"exam":         lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true),
 	"registration": lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
@@ -127,36 +303,82 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
+	if m.prompting != promptNone {
+		// Keys go to the prompt, everything else also to the table.
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m.updatePrompt(msg)
+		}
+		m.prompt, cmd = m.prompt.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		width := min(msg.Width, m.width)
 		height := msg.Height
 		m.help.Width = width
+		m.previewWidth = width
 		m.table = m.table.
 			WithTargetWidth(width).
-			WithPageSize(height - 5)
+			WithPageSize(height - 7 - previewLines)
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, m.keys.RowOpen):
 			if m.table.GetIsFilterInputFocused() {
 				break
 			}
-			rows := m.table.SelectedRows()
-			if len(rows) == 0 {
-				rows = []table.Row{m.table.HighlightedRow()}
-			}
+			rows := m.targetRows()
 			for _, row := range rows {
 				if val, ok := row.Data[columnKeyLink]; ok && val != nil {
 					link := val.(string)
-					if err := openURL(link); err != nil {
+					if !common.IsWebURL(link) {
+						fmt.Println("Not opening a link that is not a web page:", link)
+						continue
+					}
+					if err := common.OpenURL(link); err != nil {
 						fmt.Println("Error opening URL:", err)
 					}
 				}
 			}
+			m = m.setRead(rows, true)
 			// Reset filter and selection after opening
 			m.table = m.table.
 				WithFilterInputValue("").
 				WithAllRowsDeselected()
+		case key.Matches(msg, m.keys.ToggleRead):
+			if m.table.GetIsFilterInputFocused() {
+				break
+			}
+			rows := m.targetRows()
+			read, _ := rows[0].Data[columnKeyRead].(bool)
+			m = m.setRead(rows, !read)
+			m.table = m.table.WithAllRowsDeselected()
+		case key.Matches(msg, m.keys.ToggleStar):
+			if m.table.GetIsFilterInputFocused() {
+				break
+			}
+			m = m.toggleStar(m.targetRows())
+			m.table = m.table.WithAllRowsDeselected()
+		case key.Matches(msg, m.keys.EditNote):
+			if m.table.GetIsFilterInputFocused() {
+				break
+			}
+			var cmd tea.Cmd
+			m, cmd = m.editNote(m.table.HighlightedRow())
+			return m, cmd
+		case key.Matches(msg, m.keys.Search):
+			if m.table.GetIsFilterInputFocused() {
+				break
+			}
+			var cmd tea.Cmd
+			m, cmd = m.openPrompt(promptSearch, m.query)
+			return m, cmd
+		case key.Matches(msg, m.keys.StarredOnly):
+			if m.table.GetIsFilterInputFocused() {
+				break
+			}
+			m.starredOnly = !m.starredOnly
+			m = m.reload()
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
 		case key.Matches(msg, m.keys.Quit):
@@ -176,5 +398,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) View() string {
 	tableView := m.table.View()
 	helpView := m.help.View(m.keys)
-	return lipgloss.JoinVertical(lipgloss.Left, tableView, helpView)
+	if m.prompting != promptNone {
+		helpView = m.prompt.View()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.titleView(), tableView, m.previewView(), helpView)
+}
+
+func (m Model) titleView() string {
+	counter := "all read"
+	if m.unread > 0 {
+		counter = fmt.Sprintf("%d unread", m.unread)
+	}
+	if m.starredOnly {
+		counter += " · starred only"
+	}
+	if m.query != "" {
+		counter += fmt.Sprintf(" · %d matching: %s", len(m.table.GetVisibleRows()), m.query)
+	}
+	return titleStyle.Render("AIUB Notices") + counterStyle.Render(" · "+counter)
+}
+
+func (m Model) previewView() string {
+	text, _ := m.table.HighlightedRow().Data[columnKeyPreview].(string)
+
+	// Wrap to the pane width (minus padding), then keep a fixed number of lines
+	// so the layout does not jump while moving through the table.
+	wrapped := lipgloss.NewStyle().Width(max(m.previewWidth-1, 1)).Render(text)
+	lines := strings.Split(wrapped, "\n")
+	if len(lines) > previewLines {
+		lines = lines[:previewLines]
+	}
+	for len(lines) < previewLines {
+		lines = append(lines, "")
+	}
+
+	return previewStyle.Render(strings.Join(lines, "\n"))
 }
@@ -0,0 +1,79 @@
+package list
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/AtifChy/aiub-notice/internal/notice"
+)
+
+// promptKind is what the prompt below the table is reading.
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptNote
+	promptSearch
+)
+
+var (
+	promptSubmit = key.NewBinding(key.WithKeys("enter"))
+	promptCancel = key.NewBinding(key.WithKeys("esc"))
+)
+
+func newPrompt() textinput.Model {
+	input := textinput.New()
+	input.PromptStyle = helpKeyStyle
+	input.PlaceholderStyle = helpDescStyle
+	return input
+}
+
+// openPrompt starts reading input of the given kind, starting from value.
+func (m Model) openPrompt(kind promptKind, value string) (Model, tea.Cmd) {
+	switch kind {
+	case promptNote:
+		m.prompt.Prompt = " Note: "
+		m.prompt.Placeholder = "enter to save, esc to cancel, empty to remove"
+	case promptSearch:
+		m.prompt.Prompt = " Search: "
+		m.prompt.Placeholder = `words or "a phrase", enter to search, empty to show all`
+	}
+	m.prompting = kind
+	m.prompt.SetValue(value)
+	m.prompt.CursorEnd()
+	return m, m.prompt.Focus()
+}
+
+// updatePrompt handles keys while the prompt is open.
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, promptSubmit):
+		value := strings.TrimSpace(m.prompt.Value())
+		switch m.prompting {
+		case promptNote:
+			m = m.annotate([]string{m.noteID}, func(a *notice.Annotation) {
+				a.Note = value
+			})
+		case promptSearch:
+			m.query = value
+			m = m.reload()
+			m.table = m.table.WithHighlightedRow(0)
+		}
+		return m.closePrompt(), nil
+	case key.Matches(msg, promptCancel):
+		return m.closePrompt(), nil
+	}
+
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+func (m Model) closePrompt() Model {
+	m.prompting, m.noteID = promptNone, ""
+	m.prompt.Blur()
+	return m
+}
@@ -0,0 +1,24 @@
+package notice
+
+import "time"
+
+// Annotation is what the user added to a notice: a star and a free-text
+// note. Annotations are stored apart from the notices, so refreshing the
+// cache does not touch them.
+type Annotation struct {
+	// StarredAt is when the notice was starred; zero if it is not.
+	StarredAt time.Time `json:"starred_at,omitzero"`
+	Note      string    `json:"note,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Starred reports whether the notice is starred.
+func (a Annotation) Starred() bool {
+	return !a.StarredAt.IsZero()
+}
+
+// IsZero reports whether the annotation is empty, ignoring when it was
+// last updated.
+func (a Annotation) IsZero() bool {
+	return !a.Starred() && a.Note == ""
+}
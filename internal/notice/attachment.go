@@ -0,0 +1,313 @@
+package notice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+// MaxAttachmentSize is the largest attachment that will be downloaded.
+const MaxAttachmentSize = 25 << 20 // 25 MiB
+
+// ErrAttachmentTooLarge is returned when an attachment exceeds MaxAttachmentSize.
+var ErrAttachmentTooLarge = errors.New("attachment exceeds size limit")
+
+// attachmentExts lists the file types that are treated as attachments when
+// linked from a notice body.
+var attachmentExts = map[string]struct{}{
+	".pdf": {}, ".doc": {}, ".docx": {}, ".xls": {}, ".xlsx": {},
+	".ppt": {}, ".pptx": {}, ".jpg": {}, ".jpeg": {}, ".png": {},
+	".gif": {}, ".webp": {}, ".zip": {}, ".rar": {},
+}
+
+// Attachment is a file linked from a notice's detail page. SHA256, Size and
+// ContentType are set once the file has been stored locally.
+type Attachment struct {
+	Name        string
+	URL         string
+	SHA256      string
+	Size        int64
+	ContentType string
+	FetchedAt   time.Time
+}
+
+// Downloaded reports whether the attachment has been stored locally.
+func (a Attachment) Downloaded() bool {
+	return a.SHA256 != ""
+}
+
+// findAttachments collects links to downloadable files and embedded images
+// from a notice body, resolving them against base.
+func findAttachments(body *goquery.Selection, base *url.URL) []Attachment {
+	var attachments []Attachment
+	found := make(map[string]struct{})
+
+	add := func(ref, name string) {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil || ref == "" {
+			return
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		if _, ok := attachmentExts[strings.ToLower(path.Ext(u.Path))]; !ok {
+			return
+		}
+		link := u.String()
+		if _, ok := found[link]; ok {
+			return
+		}
+		found[link] = struct{}{}
+
+		if name = collapseSpace(name); name == "" {
+			name, _ = url.PathUnescape(path.Base(u.Path))
+		}
+		attachments = append(attachments, Attachment{Name: name, URL: link})
+	}
+
+	body.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		add(href, s.Text())
+	})
+	body.Find("img[src]").Each(func(_ int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		alt, _ := s.Attr("alt")
+		add(src, alt)
+	})
+
+	return attachments
+}
+
+func getAttachmentsDir() (string, error) {
+	path, err := common.GetDataPath()
+	if err != nil {
+		return "", fmt.Errorf("get data path: %w", err)
+	}
+	return filepath.Join(path, "attachments"), nil
+}
+
+// AttachmentPath returns the location of a downloaded attachment in the
+// content-addressed store.
+func AttachmentPath(a Attachment) (string, error) {
+	if !a.Downloaded() {
+		return "", fmt.Errorf("attachment %q has not been downloaded", a.Name)
+	}
+	dir, err := getAttachmentsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, a.SHA256[:2], a.SHA256+attachmentExt(a)), nil
+}
+
+func attachmentExt(a Attachment) string {
+	if u, err := url.Parse(a.URL); err == nil {
+		if ext := path.Ext(u.Path); ext != "" {
+			return strings.ToLower(ext)
+		}
+	}
+	if exts, _ := mime.ExtensionsByType(a.ContentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// DownloadAttachment fetches the attachment and stores it under its SHA-256
+// hash. Files already present in the store are not written again.
+func DownloadAttachment(ctx context.Context, a *Attachment) error {
+	response, err := httpGetWithRetry(ctx, a.URL, currentRetryPolicy())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status code %d", response.StatusCode)
+	}
+	if response.ContentLength > MaxAttachmentSize {
+		return fmt.Errorf("%w: %d bytes", ErrAttachmentTooLarge, response.ContentLength)
+	}
+
+	dir, err := getAttachmentsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create attachments directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "download-*")
+	if err != nil {
+		return fmt.Errorf("create temp attachment file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(response.Body, MaxAttachmentSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write attachment: %w", err)
+	}
+	if size > MaxAttachmentSize {
+		return fmt.Errorf("%w: more than %d bytes", ErrAttachmentTooLarge, MaxAttachmentSize)
+	}
+
+	a.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	a.Size = size
+	a.ContentType, _, _ = mime.ParseMediaType(response.Header.Get("Content-Type"))
+	a.FetchedAt = time.Now()
+
+	dest, err := AttachmentPath(*a)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return nil // identical content already stored
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("create attachment directory: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return fmt.Errorf("move attachment into place: %w", err)
+	}
+
+	return nil
+}
+
+// attachmentStored reports whether the attachment's file is present locally.
+func attachmentStored(a Attachment) bool {
+	p, err := AttachmentPath(a)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(p)
+	return err == nil
+}
+
+// keepDownloaded copies what is known about stored attachments in cached
+// to the attachments with the same URL, so they are not downloaded again.
+func keepDownloaded(attachments, cached []Attachment) []Attachment {
+	for i := range attachments {
+		for _, c := range cached {
+			if c.URL == attachments[i].URL && c.Downloaded() {
+				c.Name = attachments[i].Name
+				attachments[i] = c
+			}
+		}
+	}
+	return attachments
+}
+
+// downloadAttachments stores every attachment of n that is not yet stored,
+// logging failures instead of aborting so one bad link does not block others.
+func downloadAttachments(ctx context.Context, n *Notice) {
+	for i := range n.Attachments {
+		a := &n.Attachments[i]
+		if attachmentStored(*a) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err := DownloadAttachment(ctx, a); err != nil {
+			logger.L().Warn("downloading attachment",
+				slog.String("notice", n.Title),
+				slog.String("url", a.URL),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		logger.L().Info("stored attachment",
+			slog.String("name", a.Name),
+			slog.String("sha256", a.SHA256),
+			slog.Int64("size", a.Size),
+		)
+	}
+}
+
+// RedownloadAttachments downloads attachments of cached notices that are
+// missing from the local store, or all of them when force is set, and
+// updates the cache. It returns the number of attachments downloaded.
+// Attachments downloaded before ctx is cancelled are kept.
+func RedownloadAttachments(ctx context.Context, force bool) (int, error) {
+	store, err := DefaultStore()
+	if err != nil {
+		return 0, err
+	}
+	notices, err := store.Notices()
+	if err != nil {
+		return 0, fmt.Errorf("load cached notices: %w", err)
+	}
+
+	var count int
+	for _, n := range notices {
+		var downloaded []Attachment
+		for _, a := range n.Attachments {
+			if !force && attachmentStored(a) {
+				continue
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			if err := DownloadAttachment(ctx, &a); err != nil {
+				logger.L().Warn("downloading attachment",
+					slog.String("notice", n.Title),
+					slog.String("url", a.URL),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			downloaded = append(downloaded, a)
+			count++
+		}
+		if len(downloaded) == 0 {
+			continue
+		}
+		if err := storeDownloaded(store, n.ID, downloaded); err != nil {
+			return count, err
+		}
+	}
+	return count, ctx.Err()
+}
+
+// storeDownloaded records downloaded attachments in the cached notice with
+// the given ID. The notice is read again, as the service may have updated
+// it during the downloads; a notice pruned in the meantime is left alone.
+func storeDownloaded(store Store, id string, downloaded []Attachment) error {
+	n, err := store.Notice(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load notice %s: %w", id, err)
+	}
+	for i := range n.Attachments {
+		for _, a := range downloaded {
+			if n.Attachments[i].URL == a.URL {
+				n.Attachments[i] = a
+			}
+		}
+	}
+	if err := store.PutNotices(n); err != nil {
+		return fmt.Errorf("update store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package notice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withTempDataDir points common.GetDataPath at a temporary directory.
+func withTempDataDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("LocalAppData", dir)
+	t.Setenv("HOME", dir)
+
+	dataPath := filepath.Join(dir, "aiub-notice")
+	if err := os.MkdirAll(dataPath, 0o755); err != nil {
+		t.Fatalf("create data dir: %v", err)
+	}
+	return dataPath
+}
+
+func Test_DownloadAttachment(t *testing.T) {
+	withTempDataDir(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write([]byte("%PDF-1.4 routine"))
+	}))
+	defer server.Close()
+
+	first := Attachment{Name: "routine", URL: server.URL + "/routine.pdf"}
+	if err := DownloadAttachment(context.Background(), &first); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	if first.Size != int64(len("%PDF-1.4 routine")) || first.ContentType != "application/pdf" {
+		t.Errorf("unexpected metadata: %+v", first)
+	}
+
+	// The same content under another URL must resolve to the same stored file.
+	second := Attachment{Name: "copy", URL: server.URL + "/copy.pdf"}
+	if err := DownloadAttachment(context.Background(), &second); err != nil {
+		t.Fatalf("download duplicate: %v", err)
+	}
+	if first.SHA256 != second.SHA256 {
+		t.Fatalf("hashes differ: %s != %s", first.SHA256, second.SHA256)
+	}
+
+	path, err := AttachmentPath(first)
+	if err != nil {
+		t.Fatalf("attachment path: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*"))
+	if len(files) != 1 {
+		t.Errorf("expected a single stored file, got %v", files)
+	}
+}
+
+func Test_RedownloadAttachments(t *testing.T) {
+	withTempDataDir(t)
+	store := mustDefaultStore(t)
+
+	var n Notice
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the service updates the notice while the file downloads
+		updated := n
+		updated.Body = "corrected schedule"
+		if err := store.PutNotices(updated); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte("%PDF-1.4 routine"))
+	}))
+	defer server.Close()
+
+	n = testNotice("/routine", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	n.Attachments = []Attachment{{Name: "routine", URL: server.URL + "/routine.pdf"}}
+	other := testNotice("/fees", time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC))
+	if err := store.PutNotices(n, other); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := RedownloadAttachments(context.Background(), false)
+	if err != nil || count != 1 {
+		t.Fatalf("RedownloadAttachments() = %d, %v", count, err)
+	}
+	got, err := store.Notice(n.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body != "corrected schedule" {
+		t.Errorf("update made during the download was lost: body %q", got.Body)
+	}
+	if !got.Attachments[0].Downloaded() || !attachmentStored(got.Attachments[0]) {
+		t.Errorf("attachment not recorded: %+v", got.Attachments[0])
+	}
+
+	if count, err := RedownloadAttachments(context.Background(), false); err != nil || count != 0 {
+		t.Errorf("second RedownloadAttachments() = %d, %v", count, err)
+	}
+}
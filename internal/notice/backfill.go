@@ -0,0 +1,178 @@
+package notice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+// BackfillState records the progress of an archive crawl so that an
+// interrupted backfill can resume from the next unfetched page.
+type BackfillState struct {
+	NextPage  int       `json:"next_page"`
+	Fetched   int       `json:"fetched"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// LastPage holds the IDs of the last page stored, so that a resumed
+	// crawl recognises the listing repeating its final page.
+	LastPage []string `json:"last_page,omitempty"`
+}
+
+// BackfillOptions controls how Backfill crawls the archive.
+type BackfillOptions struct {
+	// MaxPages limits the number of pages fetched in this run. Zero means no limit.
+	MaxPages int
+	// Delay is the pause between page requests.
+	Delay time.Duration
+	// Progress, if set, is called after each page is stored.
+	Progress func(page, count int)
+}
+
+func loadBackfillStates() (map[string]BackfillState, error) {
+	states := make(map[string]BackfillState)
+
+	store, err := DefaultStore()
+	if err != nil {
+		return states, err
+	}
+	if _, err := store.Meta(metaBackfill, &states); err != nil {
+		return make(map[string]BackfillState), err
+	}
+	return states, nil
+}
+
+// LoadBackfillState returns the saved backfill progress of the named source,
+// or a fresh state starting at the first page if it has not been backfilled.
+func LoadBackfillState(source string) (BackfillState, error) {
+	states, err := loadBackfillStates()
+	if err != nil {
+		return BackfillState{NextPage: 1}, err
+	}
+
+	state, ok := states[source]
+	if !ok || state.NextPage < 1 {
+		state.NextPage = 1
+	}
+	return state, nil
+}
+
+func storeBackfillStates(states map[string]BackfillState) error {
+	store, err := DefaultStore()
+	if err != nil {
+		return err
+	}
+	return store.SetMeta(metaBackfill, states)
+}
+
+func saveBackfillState(source string, state BackfillState) error {
+	states, err := loadBackfillStates()
+	if err != nil {
+		return err
+	}
+	states[source] = state
+
+	return storeBackfillStates(states)
+}
+
+// ResetBackfillState discards any saved backfill progress of the named source.
+func ResetBackfillState(source string) error {
+	states, err := loadBackfillStates()
+	if err != nil {
+		// An unreadable state file cannot be resumed from anyway.
+		logger.L().Warn("discarding unreadable backfill state", slog.String("error", err.Error()))
+		states = make(map[string]BackfillState)
+	}
+	if _, ok := states[source]; !ok {
+		return nil
+	}
+	delete(states, source)
+
+	return storeBackfillStates(states)
+}
+
+// Backfill crawls the archive of src page by page, starting where the last
+// run stopped, and merges every notice into the local cache. Crawled notices
+// are also marked as seen so the service does not notify about old notices.
+// The crawl is finished once a page returns no notices or only repeats
+// notices already fetched, in this run or on the last page stored by an
+// earlier one. A finished crawl is checked again from the page where it
+// ended, so pages added to the end of the archive since are picked up.
+func Backfill(ctx context.Context, src SourceConfig, opts BackfillOptions) (BackfillState, error) {
+	state, err := LoadBackfillState(src.Name)
+	if err != nil {
+		return state, err
+	}
+	state.Done = false
+
+	store, err := DefaultStore()
+	if err != nil {
+		return state, err
+	}
+
+	fetched := make(map[string]struct{}, len(state.LastPage))
+	for _, id := range state.LastPage {
+		fetched[id] = struct{}{}
+	}
+	for pages := 0; opts.MaxPages <= 0 || pages < opts.MaxPages; pages++ {
+		if pages > 0 && opts.Delay > 0 {
+			if err := sleep(ctx, opts.Delay); err != nil {
+				return state, err
+			}
+		}
+
+		page := state.NextPage
+		notices, err := GetNoticePage(ctx, src, page)
+		if err != nil && !(errors.Is(err, ErrNoNotices) && page > 1) {
+			return state, fmt.Errorf("fetch page %d: %w", page, err)
+		}
+
+		if len(notices) == 0 || allSeen(notices, fetched) {
+			state.Done = true
+			state.UpdatedAt = time.Now()
+			logger.L().Info("backfill reached the end of the archive",
+				slog.String("source", src.Name),
+				slog.Int("page", page),
+			)
+			return state, saveBackfillState(src.Name, state)
+		}
+
+		if _, err := mergeCachedNotices(notices, nil); err != nil {
+			return state, fmt.Errorf("cache page %d: %w", page, err)
+		}
+		ids := make([]string, 0, len(notices))
+		for _, n := range notices {
+			fetched[n.ID] = struct{}{}
+			ids = append(ids, n.ID)
+		}
+		if err := store.MarkSeen(ids...); err != nil {
+			return state, fmt.Errorf("save seen notices: %w", err)
+		}
+
+		state.NextPage = page + 1
+		state.Fetched += len(notices)
+		state.LastPage = ids
+		state.UpdatedAt = time.Now()
+		if err := saveBackfillState(src.Name, state); err != nil {
+			return state, fmt.Errorf("save backfill state: %w", err)
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(page, len(notices))
+		}
+	}
+
+	return state, nil
+}
+
+func allSeen(notices []Notice, seen map[string]struct{}) bool {
+	for _, n := range notices {
+		if _, ok := seen[n.ID]; !ok {
+			return false
+		}
+	}
+	return true
+}
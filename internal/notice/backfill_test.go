@@ -0,0 +1,273 @@
+package notice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// listingServer serves the pages of a notice listing in the layout of
+// DefaultSource. Pages past the end are empty, or repeat the last page if
+// repeatLast is set, as some listings do. Any other path is a detail page,
+// with the body set in details if any. Both are served with an ETag.
+type listingServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	pages      [][]string
+	repeatLast bool
+	details    map[string]string
+	requested  []int
+	// detailRequests counts the detail pages sent in full.
+	detailRequests int
+}
+
+func newListingServer(t *testing.T, pages [][]string, repeatLast bool) *listingServer {
+	t.Helper()
+	s := &listingServer{pages: pages, repeatLast: repeatLast}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *listingServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path != DefaultSource.ListPath {
+		body, ok := s.details[r.URL.Path]
+		if !ok {
+			body = "Details of " + r.URL.Path
+		}
+		etag := strconv.Quote(body)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.detailRequests++
+		w.Header().Set("ETag", etag)
+		_, _ = fmt.Fprintf(w, `<div class="notice-details">%s</div>`, body)
+		return
+	}
+	page := 1
+	if value := r.URL.Query().Get(DefaultSource.PageParam); value != "" {
+		page, _ = strconv.Atoi(value)
+	}
+
+	s.requested = append(s.requested, page)
+	var paths []string
+	switch {
+	case page <= len(s.pages):
+		paths = s.pages[page-1]
+	case s.repeatLast && len(s.pages) > 0:
+		paths = s.pages[len(s.pages)-1]
+	}
+
+	var b strings.Builder
+	b.WriteString("<html><body><ul>")
+	for _, path := range paths {
+		fmt.Fprintf(&b, `<li><div class="notification"><a href=%q><div class="date-custom">1 Mar 2025</div>`+
+			`<h2 class="title">%s</h2><p class="desc">About %s</p></a></div></li>`, path, path, path)
+	}
+	b.WriteString("</ul></body></html>")
+	etag := strconv.Quote(strings.Join(paths, " "))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// setPages replaces the listing, as when new notices are published.
+func (s *listingServer) setPages(pages [][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages = pages
+}
+
+// setDetail sets the body of the detail page at path.
+func (s *listingServer) setDetail(path, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.details == nil {
+		s.details = make(map[string]string)
+	}
+	s.details[path] = body
+}
+
+func (s *listingServer) source() SourceConfig {
+	src := DefaultSource
+	src.BaseURL = s.URL
+	return src
+}
+
+func (s *listingServer) id(path string) string {
+	return NoticeID(s.URL + path)
+}
+
+func Test_htmlSource_Fetch_pages(t *testing.T) {
+	pages := [][]string{{"/a", "/b"}, {"/c", "/d"}, {"/e", "/f"}}
+
+	tests := []struct {
+		name      string
+		seen      []string
+		wantPages int
+	}{
+		{name: "first run fetches the first page only", wantPages: 1},
+		{name: "stops at the page with a seen notice", seen: []string{"/d"}, wantPages: 2},
+		{name: "stops at the first page if it has a seen notice", seen: []string{"/a"}, wantPages: 1},
+		{name: "stops at the end of the listing", seen: []string{"/unknown"}, wantPages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTempDataDir(t)
+			server := newListingServer(t, pages, false)
+
+			var seen SeenSet
+			for _, path := range tt.seen {
+				seen.Add(server.id(path))
+			}
+			result, err := htmlSource{cfg: server.source()}.Fetch(context.Background(), seen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Pages != tt.wantPages || len(result.Notices) != 2*tt.wantPages {
+				t.Errorf("Fetch() = %d pages with %d notices, want %d pages", result.Pages, len(result.Notices), tt.wantPages)
+			}
+		})
+	}
+}
+
+func Test_Backfill(t *testing.T) {
+	tests := []struct {
+		name       string
+		pages      [][]string
+		repeatLast bool
+		// runs holds the page limit of each run.
+		runs []int
+		// grown, if set, replaces the listing before the last run.
+		grown        [][]string
+		wantDone     bool
+		wantNextPage int
+		wantFetched  int
+		// wantRequested lists the pages requested by the last run.
+		wantRequested []int
+	}{
+		{
+			name:          "stops at an empty page",
+			pages:         [][]string{{"/a", "/b"}, {"/c", "/d"}},
+			runs:          []int{0},
+			wantDone:      true,
+			wantNextPage:  3,
+			wantFetched:   4,
+			wantRequested: []int{1, 2, 3},
+		},
+		{
+			name:          "pauses at the page limit",
+			pages:         [][]string{{"/a", "/b"}, {"/c", "/d"}, {"/e"}},
+			runs:          []int{2},
+			wantNextPage:  3,
+			wantFetched:   4,
+			wantRequested: []int{1, 2},
+		},
+		{
+			name:          "resumes from the next page",
+			pages:         [][]string{{"/a", "/b"}, {"/c", "/d"}, {"/e"}},
+			runs:          []int{2, 0},
+			wantDone:      true,
+			wantNextPage:  4,
+			wantFetched:   5,
+			wantRequested: []int{3, 4},
+		},
+		{
+			name:          "stops when a resumed run starts on a repeated last page",
+			pages:         [][]string{{"/a", "/b"}, {"/c", "/d"}},
+			repeatLast:    true,
+			runs:          []int{2, 0},
+			wantDone:      true,
+			wantNextPage:  3,
+			wantFetched:   4,
+			wantRequested: []int{3},
+		},
+		{
+			name:          "checks a finished crawl again",
+			pages:         [][]string{{"/a", "/b"}, {"/c", "/d"}},
+			runs:          []int{0, 0},
+			wantDone:      true,
+			wantNextPage:  3,
+			wantFetched:   4,
+			wantRequested: []int{3},
+		},
+		{
+			name:          "continues a finished crawl when the archive grew",
+			pages:         [][]string{{"/a", "/b"}, {"/c", "/d"}},
+			runs:          []int{0, 0},
+			grown:         [][]string{{"/a", "/b"}, {"/c", "/d"}, {"/e", "/f"}},
+			wantDone:      true,
+			wantNextPage:  4,
+			wantFetched:   6,
+			wantRequested: []int{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTempDataDir(t)
+			server := newListingServer(t, tt.pages, tt.repeatLast)
+			src := server.source()
+
+			var state BackfillState
+			for i, maxPages := range tt.runs {
+				if i == len(tt.runs)-1 {
+					if tt.grown != nil {
+						server.setPages(tt.grown)
+					}
+					server.requested = nil
+				}
+				var err error
+				if state, err = Backfill(context.Background(), src, BackfillOptions{MaxPages: maxPages}); err != nil {
+					t.Fatalf("run %d: %v", i+1, err)
+				}
+			}
+
+			if state.Done != tt.wantDone || state.NextPage != tt.wantNextPage || state.Fetched != tt.wantFetched {
+				t.Errorf("Backfill() = %+v, want done %v at page %d with %d notices",
+					state, tt.wantDone, tt.wantNextPage, tt.wantFetched)
+			}
+			if fmt.Sprint(server.requested) != fmt.Sprint(tt.wantRequested) {
+				t.Errorf("last run requested pages %v, want %v", server.requested, tt.wantRequested)
+			}
+			saved, err := LoadBackfillState(src.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.NextPage != state.NextPage || saved.Done != state.Done {
+				t.Errorf("saved state = %+v, want %+v", saved, state)
+			}
+
+			seen, err := LoadSeen(mustDefaultStore(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(seen.IDs) != state.Fetched {
+				t.Errorf("%d notices marked as seen, want %d", len(seen.IDs), state.Fetched)
+			}
+		})
+	}
+}
+
+func mustDefaultStore(t *testing.T) Store {
+	t.Helper()
+	store, err := DefaultStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
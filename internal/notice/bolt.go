@@ -0,0 +1,511 @@
+package notice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+)
+
+// storeLockTimeout bounds how long an operation waits for another process
+// holding the database.
+const storeLockTimeout = 10 * time.Second
+
+var (
+	bucketNotices   = []byte("notices")
+	bucketByDate    = []byte("notices_by_date")
+	bucketSeen      = []byte("seen")
+	bucketRead      = []byte("read")
+	bucketRevisions = []byte("revisions")
+	bucketMeta      = []byte("meta")
+
+	bucketAnnotations = []byte("annotations")
+	bucketUnread      = []byte("unread")
+)
+
+// BoltStore is a Store kept in a bbolt database file.
+type BoltStore struct {
+	path string
+}
+
+var _ Store = (*BoltStore)(nil)
+
+// Path returns the database file of the store.
+func (s *BoltStore) Path() string { return s.path }
+
+func (s *BoltStore) open() (*bolt.DB, error) {
+	for {
+		before, _ := os.Stat(s.path)
+		db, err := bolt.Open(s.path, 0o600, &bolt.Options{Timeout: storeLockTimeout})
+		if errors.Is(err, bolterrors.ErrTimeout) {
+			return nil, fmt.Errorf("open store %s: database is locked by another process", s.path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("open store %s: %w", s.path, err)
+		}
+
+		// Compact replaces the file while holding its lock. If that happened
+		// while waiting for the lock, the lock is on the replaced file.
+		after, err := os.Stat(s.path)
+		if before != nil && err == nil && !os.SameFile(before, after) {
+			_ = db.Close()
+			continue
+		}
+		return db, nil
+	}
+}
+
+// view runs fn in a read-only transaction. A store that does not exist yet
+// reads as empty.
+func (s *BoltStore) view(fn func(tx *bolt.Tx) error) error {
+	if !storeExists(s.path) {
+		return nil
+	}
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return db.View(fn)
+}
+
+// update runs fn in a read-write transaction, migrating the store to the
+// current schema first.
+func (s *BoltStore) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return db.Update(func(tx *bolt.Tx) error {
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// dateKey orders notices by date in the date index. The sign bit is flipped
+// so that dates before 1970 sort first.
+func dateKey(date time.Time, id string) []byte {
+	key := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(key, uint64(date.Unix())^(1<<63))
+	return append(key, id...)
+}
+
+func getNotice(tx *bolt.Tx, id string) (Notice, bool, error) {
+	b := tx.Bucket(bucketNotices)
+	if b == nil {
+		return Notice{}, false, nil
+	}
+	data := b.Get([]byte(id))
+	if data == nil {
+		return Notice{}, false, nil
+	}
+	var n Notice
+	if err := json.Unmarshal(data, &n); err != nil {
+		return Notice{}, false, fmt.Errorf("decode notice %s: %w", id, err)
+	}
+	return n, true, nil
+}
+
+// noticesSince walks the date index from newest to oldest, stopping before
+// since, and returns the notices for which keep returns true.
+func noticesSince(tx *bolt.Tx, since time.Time, keep func(id string) bool) ([]Notice, error) {
+	index := tx.Bucket(bucketByDate)
+	if index == nil {
+		return nil, nil
+	}
+	floor := dateKey(since, "")
+
+	var notices []Notice
+	c := index.Cursor()
+	for k, _ := c.Last(); k != nil && bytes.Compare(k, floor) >= 0; k, _ = c.Prev() {
+		id := string(k[8:])
+		if keep != nil && !keep(id) {
+			continue
+		}
+		n, ok, err := getNotice(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			notices = append(notices, n)
+		}
+	}
+	return notices, nil
+}
+
+func (s *BoltStore) Notices() ([]Notice, error) {
+	// Undated notices carry the zero time, the earliest date there is.
+	return s.NoticesSince(time.Time{})
+}
+
+func (s *BoltStore) NoticesSince(since time.Time) ([]Notice, error) {
+	var notices []Notice
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		notices, err = noticesSince(tx, since, nil)
+		return err
+	})
+	return notices, err
+}
+
+func (s *BoltStore) Notice(id string) (Notice, error) {
+	var n Notice
+	found := false
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		n, found, err = getNotice(tx, id)
+		return err
+	})
+	if err == nil && !found {
+		err = fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return n, err
+}
+
+func (s *BoltStore) CountNotices() (int, error) {
+	count := 0
+	err := s.view(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(bucketNotices); b != nil {
+			count = b.Stats().KeyN
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (s *BoltStore) PutNotices(notices ...Notice) error {
+	if len(notices) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		return putNotices(tx, notices)
+	})
+}
+
+func putNotices(tx *bolt.Tx, notices []Notice) error {
+	b, index := tx.Bucket(bucketNotices), tx.Bucket(bucketByDate)
+	for _, n := range notices {
+		if n.ID == "" {
+			return fmt.Errorf("store notice %q: missing ID", n.Link)
+		}
+		old, ok, err := getNotice(tx, n.ID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := index.Delete(dateKey(old.Date, old.ID)); err != nil {
+				return err
+			}
+			err = indexNotice(tx, &old, n)
+		} else {
+			err = indexNotice(tx, nil, n)
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(n)
+		if err != nil {
+			return fmt.Errorf("encode notice %s: %w", n.ID, err)
+		}
+		if err := b.Put([]byte(n.ID), data); err != nil {
+			return err
+		}
+		if err := index.Put(dateKey(n.Date, n.ID), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BoltStore) PruneNotices(ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		pruneDates, err := pruneDates(tx)
+		if err != nil {
+			return err
+		}
+
+		var pruned []Notice
+		for _, id := range ids {
+			n, ok, err := getNotice(tx, id)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := unindexNotice(tx, n); err != nil {
+				return err
+			}
+			if err := tx.Bucket(bucketByDate).Delete(dateKey(n.Date, n.ID)); err != nil {
+				return err
+			}
+			for _, name := range [][]byte{bucketNotices, bucketRead, bucketUnread, bucketRevisions, bucketAnnotations} {
+				if err := tx.Bucket(name).Delete([]byte(id)); err != nil {
+					return err
+				}
+			}
+			if category := n.category(); n.Date.After(pruneDates[category]) {
+				pruneDates[category] = n.Date
+			}
+			pruned = append(pruned, n)
+		}
+
+		// Notices dated before the prune date of their category are seen by
+		// date. Those dated on it keep their seen entry, so that notices
+		// published later on the same day are still announced; undated ones
+		// have no date to go by.
+		seen := tx.Bucket(bucketSeen)
+		for _, n := range pruned {
+			if n.Date.IsZero() || !n.Date.Before(pruneDates[n.category()]) {
+				continue
+			}
+			if err := seen.Delete([]byte(n.ID)); err != nil {
+				return err
+			}
+		}
+		return putPruneDates(tx, pruneDates)
+	})
+}
+
+func (s *BoltStore) Seen() (map[string]struct{}, error) {
+	seen := make(map[string]struct{})
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSeen)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, _ []byte) error {
+			seen[string(k)] = struct{}{}
+			return nil
+		})
+	})
+	return seen, err
+}
+
+func (s *BoltStore) PruneDates() (map[string]time.Time, error) {
+	var dates map[string]time.Time
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		dates, err = pruneDates(tx)
+		return err
+	})
+	return dates, err
+}
+
+func (s *BoltStore) MarkSeen(ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSeen)
+		for _, id := range ids {
+			if err := b.Put([]byte(id), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Read() (map[string]time.Time, error) {
+	read := make(map[string]time.Time)
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketRead)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var at time.Time
+			if err := at.UnmarshalBinary(v); err != nil {
+				return fmt.Errorf("decode read time of %s: %w", k, err)
+			}
+			read[string(k)] = at
+			return nil
+		})
+	})
+	return read, err
+}
+
+func (s *BoltStore) SetRead(at time.Time, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	// Notices marked unread are recorded with the time, so that merging an
+	// older read time from another machine does not undo the change.
+	set, clear := bucketRead, bucketUnread
+	if at.IsZero() {
+		set, clear, at = bucketUnread, bucketRead, time.Now()
+	}
+	value, err := at.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		for _, id := range ids {
+			if err := tx.Bucket(set).Put([]byte(id), value); err != nil {
+				return err
+			}
+			if err := tx.Bucket(clear).Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Unread(since time.Time) ([]Notice, error) {
+	var notices []Notice
+	err := s.view(func(tx *bolt.Tx) error {
+		read := tx.Bucket(bucketRead)
+		var err error
+		notices, err = noticesSince(tx, since, func(id string) bool {
+			return read == nil || read.Get([]byte(id)) == nil
+		})
+		return err
+	})
+	return notices, err
+}
+
+func (s *BoltStore) Revisions(id string) ([]Revision, error) {
+	var revisions []Revision
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketRevisions)
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &revisions); err != nil {
+			return fmt.Errorf("decode revisions of %s: %w", id, err)
+		}
+		return nil
+	})
+	return revisions, err
+}
+
+func (s *BoltStore) SetRevisions(id string, revisions []Revision) error {
+	data, err := json.Marshal(revisions)
+	if err != nil {
+		return fmt.Errorf("encode revisions of %s: %w", id, err)
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketRevisions).Put([]byte(id), data)
+	})
+}
+
+func (s *BoltStore) RevisedNotices() ([]string, error) {
+	var ids []string
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketRevisions)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, _ []byte) error {
+			ids = append(ids, string(k))
+			return nil
+		})
+	})
+	sort.Strings(ids)
+	return ids, err
+}
+
+func (s *BoltStore) Annotations() (map[string]Annotation, error) {
+	annotations := make(map[string]Annotation)
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketAnnotations)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var a Annotation
+			if err := json.Unmarshal(v, &a); err != nil {
+				return fmt.Errorf("decode annotation of %s: %w", k, err)
+			}
+			if !a.IsZero() {
+				annotations[string(k)] = a
+			}
+			return nil
+		})
+	})
+	return annotations, err
+}
+
+func (s *BoltStore) Annotation(id string) (Annotation, error) {
+	var a Annotation
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketAnnotations)
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &a); err != nil {
+			return fmt.Errorf("decode annotation of %s: %w", id, err)
+		}
+		return nil
+	})
+	return a, err
+}
+
+func (s *BoltStore) SetAnnotation(id string, a Annotation) error {
+	if a.IsZero() && a.UpdatedAt.IsZero() {
+		return s.update(func(tx *bolt.Tx) error {
+			return tx.Bucket(bucketAnnotations).Delete([]byte(id))
+		})
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("encode annotation of %s: %w", id, err)
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketAnnotations).Put([]byte(id), data)
+	})
+}
+
+func (s *BoltStore) Meta(key string, v any) (bool, error) {
+	found := false
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketMeta)
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		found = true
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("decode %s: %w", key, err)
+		}
+		return nil
+	})
+	return found, err
+}
+
+func (s *BoltStore) SetMeta(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketMeta).Put([]byte(key), data)
+	})
+}
@@ -1,98 +1,192 @@
 package notice
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"log/slog"
+	"time"
 
-	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
 )
 
-func getCachedNoticesPath() (string, error) {
-	path, err := common.GetDataPath()
+// lastSeenInterval is how often checks finding a listing unchanged update
+// the LastSeen time of its notices, so that quiet checks rarely write to
+// the store.
+const lastSeenInterval = time.Hour
+
+// CacheResult merges the notices of a fetch into the store along with the
+// validators of its responses. It returns the already cached notices whose
+// content has changed. Unchanged results carry no listed notices; the
+// notices listed by the last changed result of the source are marked as
+// seen on the listing again instead, and its edited notices are merged.
+func CacheResult(result FetchResult) ([]Notice, error) {
+	store, err := DefaultStore()
 	if err != nil {
-		return "", fmt.Errorf("get data path: %w", err)
+		return nil, err
 	}
-	return filepath.Join(path, "notices.json"), nil
-}
+	listed := loadListed(store)
 
-func storeCachedNotices(notices []Notice) error {
-	path, err := getCachedNoticesPath()
+	if !result.Changed() {
+		if err := touchListed(store, listed[result.Source], result.FetchedAt); err != nil {
+			return nil, err
+		}
+		if len(result.Edited) == 0 {
+			return nil, storeValidators(result.validators)
+		}
+		updated, err := mergeCachedNotices(result.Edited, nil)
+		if err != nil {
+			return nil, err
+		}
+		return updated, storeValidators(result.validators)
+	}
+	updated, err := mergeCachedNotices(result.Notices, result.Withdrawn)
 	if err != nil {
-		return fmt.Errorf("get cache path: %w", err)
+		return nil, err
 	}
 
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("create cache file: %w", err)
+	ids := make([]string, 0, len(result.Notices))
+	for _, n := range result.Notices {
+		ids = append(ids, n.ID)
+	}
+	listed[result.Source] = ids
+	if err := store.SetMeta(metaListed, listed); err != nil {
+		return updated, fmt.Errorf("save listed notices: %w", err)
 	}
-	defer func() { _ = file.Close() }()
+	return updated, storeValidators(result.validators)
+}
 
-	return json.NewEncoder(file).Encode(notices)
+// loadListed returns the IDs of the notices listed by the last changed
+// result of each source, keyed by source name.
+func loadListed(store Store) map[string][]string {
+	listed := make(map[string][]string)
+	if _, err := store.Meta(metaListed, &listed); err != nil {
+		logger.L().Warn("loading listed notices", slog.String("error", err.Error()))
+		return make(map[string][]string)
+	}
+	return listed
 }
 
-func GetCachedNotices() ([]Notice, error) {
-	path, err := getCachedNoticesPath()
-	if err != nil {
-		return nil, fmt.Errorf("get cache path: %w", err)
+// touchListed sets the LastSeen time of the notices with the given IDs to
+// at, unless it was updated within lastSeenInterval.
+func touchListed(store Store, ids []string, at time.Time) error {
+	var touched []Notice
+	for _, id := range ids {
+		n, err := store.Notice(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if at.Sub(n.LastSeen) < lastSeenInterval {
+			continue
+		}
+		n.LastSeen = at
+		touched = append(touched, n)
 	}
+	return store.PutNotices(touched...)
+}
 
-	file, err := os.Open(path)
+// mergeCachedNotices merges notices into the store, replacing entries with
+// the same ID and keeping the rest, so that the store is an append-only
+// archive: notices which have moved past the fetched pages stay available to
+// list and last. A notice cross-posted to several categories keeps the
+// category it was first cached under, and every notice keeps the time it was
+// first seen.
+//
+// Cached notices whose content changed are returned, and their old and new
+// content is recorded as revisions, so the original content of a notice
+// stays available as its first revision. Cached notices listed in withdrawn
+// are flagged as such; a withdrawn notice that is listed again is restored.
+func mergeCachedNotices(notices, withdrawn []Notice) ([]Notice, error) {
+	store, err := DefaultStore()
 	if err != nil {
-		return nil, fmt.Errorf("open cache file: %w", err)
+		return nil, err
+	}
+	merged := make(map[string]Notice, len(notices))
+	cached := func(id string) (Notice, bool, error) {
+		if n, ok := merged[id]; ok {
+			return n, true, nil
+		}
+		n, err := store.Notice(id)
+		if errors.Is(err, ErrNotFound) {
+			return Notice{}, false, nil
+		}
+		return n, err == nil, err
 	}
-	defer func() { _ = file.Close() }()
 
-	var notices []Notice
-	if err := json.NewDecoder(file).Decode(&notices); err != nil {
-		return nil, fmt.Errorf("decode cache file: %w", err)
+	now := time.Now()
+	var updated []Notice
+	for _, n := range notices {
+		n.Hash = n.ContentHash()
+		n.FirstSeen, n.LastSeen = now, now
+		old, ok, err := cached(n.ID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			n.FirstSeen = old.FirstSeen
+			if !n.HasDetail() && old.HasDetail() {
+				n.setDetail(old.detail())
+				n.Hash = n.ContentHash()
+			}
+			if old.Category != "" {
+				n.Category = old.Category
+			}
+			if contentChanged(old, n) {
+				if err := recordRevision(store, old, n); err != nil {
+					logger.L().Warn("recording notice revision",
+						slog.String("id", n.ID),
+						slog.String("error", err.Error()),
+					)
+				}
+				updated = append(updated, n)
+			}
+		}
+		merged[n.ID] = n
 	}
 
-	return notices, nil
-}
+	for _, n := range withdrawn {
+		c, ok, err := cached(n.ID)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !c.Withdrawn() {
+			c.WithdrawnAt = now
+			merged[n.ID] = c
+		}
+	}
 
-// GetSeenNoticesPath returns the path to the file where seen notices are stored.
-func GetSeenNoticesPath() (string, error) {
-	path, err := common.GetDataPath()
-	if err != nil {
-		return "", err
+	changed := make([]Notice, 0, len(merged))
+	for _, n := range merged {
+		changed = append(changed, n)
 	}
-	return filepath.Join(path, "seen_notices.json"), nil
+	return updated, store.PutNotices(changed...)
 }
 
-func LoadSeenNotices() (map[string]struct{}, error) {
-	path, err := GetSeenNoticesPath()
-	if err != nil {
-		return nil, fmt.Errorf("get seen notices file path: %w", err)
-	}
-	file, err := os.Open(path)
-	if os.IsNotExist(err) {
-		return make(map[string]struct{}), nil
+// contentChanged reports whether the content of a notice differs between
+// two versions. The body is only compared if both versions have details,
+// so fetching the details of a cached notice does not count as a change.
+func contentChanged(old, n Notice) bool {
+	if old.HasDetail() != n.HasDetail() {
+		old.Body, n.Body = "", ""
 	}
+	return old.ContentHash() != n.ContentHash()
+}
+
+// GetCachedNotices returns all notices in the default store, newest first.
+func GetCachedNotices() ([]Notice, error) {
+	store, err := DefaultStore()
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = file.Close() }()
-
-	var seen map[string]struct{}
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&seen); err != nil {
-		return make(map[string]struct{}), nil // fallback to empty map if decoding fails
-	}
-	return seen, nil
+	return store.Notices()
 }
 
-func SaveSeenNotices(seen map[string]struct{}) error {
-	path, err := GetSeenNoticesPath()
+func countCachedNotices() (int, error) {
+	store, err := DefaultStore()
 	if err != nil {
-		return fmt.Errorf("get seen notices file path: %w", err)
+		return 0, err
 	}
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = file.Close() }()
-
-	return json.NewEncoder(file).Encode(seen)
+	return store.CountNotices()
 }
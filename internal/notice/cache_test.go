@@ -0,0 +1,97 @@
+package notice
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_mergeCachedNotices_archive(t *testing.T) {
+	withTempDataDir(t)
+
+	newNotice := func(path string, date time.Time) Notice {
+		link := "https://www.aiub.edu" + path
+		return Notice{ID: NoticeID(link), Link: link, Title: path, Date: date}
+	}
+	older := newNotice("/older", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := newNotice("/newer", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	if _, err := mergeCachedNotices([]Notice{older}, nil); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := GetCachedNotices()
+	if len(first) != 1 || first[0].FirstSeen.IsZero() || !first[0].FirstSeen.Equal(first[0].LastSeen) {
+		t.Fatalf("sighting times not set: %+v", first)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	// The older notice has left the listing; it must stay in the archive.
+	if _, err := mergeCachedNotices([]Notice{newer}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mergeCachedNotices([]Notice{newer, older}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, err := GetCachedNotices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached) != 2 || cached[0].ID != newer.ID || cached[1].ID != older.ID {
+		t.Fatalf("unexpected archive: %+v", cached)
+	}
+	if !cached[1].FirstSeen.Equal(first[0].FirstSeen) {
+		t.Errorf("first sighting changed: %v != %v", cached[1].FirstSeen, first[0].FirstSeen)
+	}
+	if !cached[1].LastSeen.After(cached[1].FirstSeen) {
+		t.Errorf("last sighting not updated: %v", cached[1].LastSeen)
+	}
+}
+
+func Test_CacheResult_lastSeen(t *testing.T) {
+	withTempDataDir(t)
+	store := mustDefaultStore(t)
+
+	listed := testNotice("/listed", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	agedOff := testNotice("/aged-off", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+	if _, err := mergeCachedNotices([]Notice{agedOff}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CacheResult(FetchResult{Source: "notices", Status: FetchChanged, Notices: []Notice{listed}}); err != nil {
+		t.Fatal(err)
+	}
+	lastSeen := func(n Notice) time.Time {
+		t.Helper()
+		cached, err := store.Notice(n.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cached.LastSeen
+	}
+	seenAt, agedOffAt := lastSeen(listed), lastSeen(agedOff)
+
+	tests := []struct {
+		name      string
+		source    string
+		fetchedAt time.Time
+		want      time.Time
+	}{
+		{name: "within the interval", source: "notices", fetchedAt: seenAt.Add(lastSeenInterval / 2), want: seenAt},
+		{name: "after the interval", source: "notices", fetchedAt: seenAt.Add(lastSeenInterval), want: seenAt.Add(lastSeenInterval)},
+		{name: "other source", source: "news", fetchedAt: seenAt.Add(3 * lastSeenInterval), want: seenAt.Add(lastSeenInterval)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FetchResult{Source: tt.source, Status: FetchNotModified, FetchedAt: tt.fetchedAt}
+			if _, err := CacheResult(result); err != nil {
+				t.Fatal(err)
+			}
+			if got := lastSeen(listed); !got.Equal(tt.want) {
+				t.Errorf("LastSeen = %v, want %v", got, tt.want)
+			}
+			if got := lastSeen(agedOff); !got.Equal(agedOffAt) {
+				t.Errorf("LastSeen of a notice no longer listed changed to %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package notice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+var detailTimeLayouts = []string{
+	"2 Jan 2006 3:04 PM",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	time.RFC3339,
+}
+
+// Detail holds the content scraped from a notice's own page.
+type Detail struct {
+	Body        string
+	BodyHTML    string
+	Posted      time.Time
+	Author      string
+	Attachments []Attachment
+}
+
+// detailRecheckAge is how long after a notice was first seen its page is
+// checked again for edits to the body, which leave the listing unchanged.
+// The checks are conditional, so an unchanged page costs little.
+const detailRecheckAge = 14 * 24 * time.Hour
+
+// GetNoticeDetail fetches the notice page at link and extracts its full body,
+// posted time and author using the detail selectors of src.
+func GetNoticeDetail(ctx context.Context, src SourceConfig, link string) (Detail, error) {
+	detail, _, err := getNoticeDetail(ctx, src, link)
+	return detail, err
+}
+
+// getNoticeDetail is GetNoticeDetail, also returning the validator of the
+// response for later conditional checks of the page.
+func getNoticeDetail(ctx context.Context, src SourceConfig, link string) (Detail, Validator, error) {
+	response, err := httpGetWithRetry(ctx, link, currentRetryPolicy())
+	if err != nil {
+		return Detail{}, Validator{}, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if pageGone(response.StatusCode) {
+		return Detail{}, Validator{}, fmt.Errorf("%w: received status code %d", ErrNoticeGone, response.StatusCode)
+	}
+	if response.StatusCode != http.StatusOK {
+		return Detail{}, Validator{}, fmt.Errorf("received status code %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return Detail{}, Validator{}, fmt.Errorf("read response: %w", err)
+	}
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return Detail{}, Validator{}, fmt.Errorf("parse HTML: %w", err)
+	}
+
+	detail, err := parseDetail(src, document, response.Request.URL)
+	return detail, newValidator(response.Header, body), err
+}
+
+// recheckNoticeDetail fetches the notice page at link again, sending the
+// validators of the previous check. The details are only parsed and
+// returned when the page changed; the returned validator must be saved.
+func recheckNoticeDetail(ctx context.Context, src SourceConfig, link string) (Detail, Validator, FetchStatus, error) {
+	body, validator, status, err := conditionalGet(ctx, link)
+	if err != nil || status != FetchChanged {
+		return Detail{}, validator, status, err
+	}
+
+	base, err := url.Parse(link)
+	if err != nil {
+		return Detail{}, validator, status, fmt.Errorf("parse link: %w", err)
+	}
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return Detail{}, validator, status, fmt.Errorf("parse HTML: %w", err)
+	}
+	detail, err := parseDetail(src, document, base)
+	return detail, validator, status, err
+}
+
+// parseDetail extracts the detail content from document. Relative attachment
+// links are resolved against base.
+func parseDetail(src SourceConfig, document *goquery.Document, base *url.URL) (Detail, error) {
+	var body *goquery.Selection
+	for _, sel := range src.Selectors.DetailBody {
+		if s := document.Find(sel).First(); s.Length() > 0 {
+			body = s
+			break
+		}
+	}
+	if body == nil {
+		return Detail{}, fmt.Errorf("notice body not found")
+	}
+
+	var detail Detail
+
+	postedSel := src.Selectors.DetailPosted
+	dateStr := collapseSpace(body.Find(postedSel).First().Text())
+	if dateStr == "" {
+		dateStr = collapseSpace(document.Find(postedSel).First().Text())
+	}
+	if dateStr != "" {
+		layouts := append(append([]string{}, detailTimeLayouts...), src.DateLayouts...)
+		if t, err := src.parseDate(dateStr, layouts); err == nil {
+			detail.Posted = t
+		}
+	}
+
+	detail.Author = collapseSpace(document.Find(src.Selectors.DetailAuthor).First().Text())
+
+	bodyHTML, err := body.Html()
+	if err != nil {
+		return Detail{}, fmt.Errorf("render body: %w", err)
+	}
+	detail.BodyHTML = strings.TrimSpace(bodyHTML)
+	detail.Body = htmlToText(body)
+	detail.Attachments = findAttachments(body, base)
+
+	return detail, nil
+}
+
+// collapseSpace trims s and replaces runs of white space, including
+// non-breaking spaces, with a single space.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// blockElements start a new line when converting HTML to plain text.
+var blockElements = map[string]struct{}{
+	"p": {}, "div": {}, "br": {}, "li": {}, "tr": {}, "table": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"ul": {}, "ol": {}, "section": {}, "article": {}, "blockquote": {},
+}
+
+// htmlToText renders the selection as plain text, keeping one line per block
+// element and collapsing whitespace inside each line.
+func htmlToText(sel *goquery.Selection) string {
+	var b strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if n.Data == "script" || n.Data == "style" {
+				return
+			}
+		}
+
+		_, block := blockElements[n.Data]
+		if block && n.Type == html.ElementNode {
+			b.WriteByte('\n')
+		}
+		if n.Type == html.ElementNode && n.Data == "li" {
+			b.WriteString("- ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block && n.Type == html.ElementNode {
+			b.WriteByte('\n')
+		}
+	}
+	for _, n := range sel.Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(b.String(), "\n") {
+		if line = collapseSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
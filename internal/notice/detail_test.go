@@ -0,0 +1,103 @@
+package notice
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func Test_parseDetail(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		wantBody string
+		wantAuth string
+		wantTime string
+		wantErr  bool
+	}{
+		{
+			name: "paragraphs and list",
+			html: `<div class="notice-details">
+				<div class="date-custom">12 Mar 2025 10:30 AM</div>
+				<p>Dear   students,</p>
+				<ul><li>Bring your ID</li><li>Arrive early</li></ul>
+				<script>alert(1)</script>
+			</div>
+			<div class="department">Office of the Registrar</div>`,
+			wantBody: "12 Mar 2025 10:30 AM\nDear students,\n- Bring your ID\n- Arrive early",
+			wantAuth: "Office of the Registrar",
+			wantTime: "2025-03-12T10:30:00+06:00",
+		},
+		{
+			name:     "fallback selector without metadata",
+			html:     `<article><p>Classes are suspended.</p></article>`,
+			wantBody: "Classes are suspended.",
+		},
+		{
+			name:    "missing body",
+			html:    `<div class="other">nothing here</div>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("parse fixture: %v", err)
+			}
+
+			got, err := parseDetail(DefaultSource, doc, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Body != tt.wantBody {
+				t.Errorf("body = %q, want %q", got.Body, tt.wantBody)
+			}
+			if got.Author != tt.wantAuth {
+				t.Errorf("author = %q, want %q", got.Author, tt.wantAuth)
+			}
+			if tt.wantTime != "" && got.Posted.Format(time.RFC3339) != tt.wantTime {
+				t.Errorf("posted = %s, want %s", got.Posted.Format(time.RFC3339), tt.wantTime)
+			}
+		})
+	}
+}
+
+func Test_findAttachments(t *testing.T) {
+	html := `<div class="notice-details">
+		<a href="/Files/Uploads/routine%20final.pdf">Final Routine</a>
+		<a href="https://cdn.aiub.edu/fees.XLSX"></a>
+		<a href="/Files/Uploads/routine%20final.pdf">duplicate</a>
+		<a href="/notice/other-notice">not a file</a>
+		<img src="images/banner.png" alt="Banner">
+	</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("parse fixture: %v", err)
+	}
+	base, _ := url.Parse("https://www.aiub.edu/notice/some-notice/")
+
+	got := findAttachments(doc.Find("div.notice-details"), base)
+	want := []Attachment{
+		{Name: "Final Routine", URL: "https://www.aiub.edu/Files/Uploads/routine%20final.pdf"},
+		{Name: "fees.XLSX", URL: "https://cdn.aiub.edu/fees.XLSX"},
+		{Name: "Banner", URL: "https://www.aiub.edu/notice/some-notice/images/banner.png"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d attachments, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("attachment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
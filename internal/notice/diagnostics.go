@@ -0,0 +1,234 @@
+package notice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+// ErrNoNotices is returned when a listing page contains no usable notices,
+// typically because the site layout changed and the selectors no longer
+// match.
+var ErrNoNotices = errors.New("no notices found")
+
+// maxDumps is the number of HTML dumps kept in the dumps directory.
+const maxDumps = 20
+
+// FieldError describes a field of a listed notice that could not be parsed.
+type FieldError struct {
+	// Item is the position of the notice on the page, starting at 1.
+	Item  int
+	Field string
+	Value string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("item %d: %s %q: %v", e.Item, e.Field, e.Value, e.Err)
+}
+
+func (e FieldError) Unwrap() error { return e.Err }
+
+// PageReport describes how a listing page was parsed.
+type PageReport struct {
+	Source string
+	URL    string
+	Page   int
+	// Items is the number of elements matched by the item selector.
+	Items int
+	// Notices holds the items that parsed without errors, and those whose
+	// only error is their date, with a zero date.
+	Notices []Notice
+	// Errors lists the fields that could not be parsed. Items with errors
+	// in other fields than the date are left out of Notices.
+	Errors []FieldError
+}
+
+// Err returns ErrNoNotices if the page yielded no usable notices.
+func (r PageReport) Err() error {
+	if len(r.Notices) > 0 {
+		return nil
+	}
+	if r.Items == 0 {
+		return fmt.Errorf("%w: item selector matched nothing", ErrNoNotices)
+	}
+	return fmt.Errorf("%w: all %d items failed to parse: %w", ErrNoNotices, r.Items, r.Errors[0])
+}
+
+// InspectPage fetches a listing page of src and reports what the parser
+// found, without touching the cache. The raw body is returned as well so
+// it can be dumped with DumpHTML.
+func InspectPage(ctx context.Context, src SourceConfig, page int) (PageReport, []byte, error) {
+	report := PageReport{Source: src.Name, URL: src.pageURL(page), Page: page}
+
+	response, err := httpGetWithRetry(ctx, report.URL, currentRetryPolicy())
+	if err != nil {
+		return report, nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return report, nil, fmt.Errorf("read response: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return report, body, fmt.Errorf("received status code %d", response.StatusCode)
+	}
+
+	parsed, err := parseNoticePage(src, body)
+	if err != nil {
+		return report, body, err
+	}
+	parsed.URL, parsed.Page = report.URL, page
+	return parsed, body, nil
+}
+
+// checkPage logs the problems of a parsed page and dumps its HTML to the
+// data directory if they differ from those of the last check of the page,
+// so a problem that persists does not rotate out the other dumps. It
+// returns the report's error.
+func checkPage(report PageReport, body []byte) error {
+	err := report.Err()
+	if err == nil && len(report.Errors) == 0 {
+		recordDiagnosis(report, "")
+		return nil
+	}
+
+	// An empty page past the end of the listing is expected.
+	if err != nil && report.Items == 0 && report.Page > 1 {
+		return err
+	}
+
+	attrs := []any{
+		slog.String("source", report.Source),
+		slog.String("url", report.URL),
+	}
+	if !recordDiagnosis(report, report.diagnosis()) {
+		attrs = append(attrs, slog.Bool("dumped_before", true))
+	} else if path, dumpErr := DumpHTML(report.Source, report.Page, body); dumpErr != nil {
+		attrs = append(attrs, slog.String("dump_error", dumpErr.Error()))
+	} else {
+		attrs = append(attrs, slog.String("dump", path))
+	}
+
+	for _, fe := range report.Errors {
+		logger.L().Warn("parsing notice field", append(attrs, slog.String("error", fe.Error()))...)
+	}
+	if err != nil {
+		logger.L().Error("parsing notice page", append(attrs, slog.String("error", err.Error()))...)
+	}
+	return err
+}
+
+// diagnosis summarizes the problems of the page. Item positions are left
+// out, so a broken item moving down the listing as notices are published
+// keeps the same diagnosis.
+func (r PageReport) diagnosis() string {
+	lines := make([]string, 0, len(r.Errors)+1)
+	for _, fe := range r.Errors {
+		lines = append(lines, fmt.Sprintf("%s %q: %v", fe.Field, fe.Value, fe.Err))
+	}
+	sort.Strings(lines)
+	if r.Err() != nil {
+		lines = append(lines, ErrNoNotices.Error())
+	}
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordDiagnosis saves the diagnosis of the page of report, which is
+// empty for a page without problems, and reports whether it differs from
+// the one saved by the last check of the page.
+func recordDiagnosis(report PageReport, diagnosis string) bool {
+	store, err := DefaultStore()
+	if err != nil {
+		return true
+	}
+	diagnosed := make(map[string]string)
+	if _, err := store.Meta(metaDiagnosed, &diagnosed); err != nil {
+		logger.L().Warn("loading page diagnoses", slog.String("error", err.Error()))
+		diagnosed = make(map[string]string)
+	}
+
+	key := fmt.Sprintf("%s/%d", report.Source, report.Page)
+	if diagnosed[key] == diagnosis {
+		return false
+	}
+	if diagnosis == "" {
+		delete(diagnosed, key)
+	} else {
+		diagnosed[key] = diagnosis
+	}
+	if err := store.SetMeta(metaDiagnosed, diagnosed); err != nil {
+		logger.L().Warn("saving page diagnoses", slog.String("error", err.Error()))
+	}
+	return true
+}
+
+func getDumpsDir() (string, error) {
+	path, err := common.GetDataPath()
+	if err != nil {
+		return "", fmt.Errorf("get data path: %w", err)
+	}
+	return filepath.Join(path, "dumps"), nil
+}
+
+// DumpHTML stores body in the dumps directory of the data directory and
+// returns the path of the file. Only the most recent dumps are kept.
+func DumpHTML(source string, page int, body []byte) (string, error) {
+	dir, err := getDumpsDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create dumps directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-page%d-%s.html", source, page, time.Now().Format("20060102-150405.000"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return "", fmt.Errorf("write dump: %w", err)
+	}
+
+	pruneDumps(dir)
+	return path, nil
+}
+
+// pruneDumps removes all but the newest maxDumps files from dir.
+func pruneDumps(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) <= maxDumps {
+		return
+	}
+
+	type dump struct {
+		path    string
+		modTime time.Time
+	}
+	dumps := make([]dump, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		dumps = append(dumps, dump{filepath.Join(dir, e.Name()), info.ModTime()})
+	}
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].modTime.After(dumps[j].modTime) })
+
+	for _, d := range dumps[min(maxDumps, len(dumps)):] {
+		_ = os.Remove(d.path)
+	}
+}
@@ -0,0 +1,152 @@
+package notice
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_parseNoticePage_diagnostics(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		wantItems   int
+		wantNotices int
+		wantFields  []string
+		wantErr     bool
+	}{
+		{
+			name: "valid item",
+			html: `<div class="notification"><a href="/n/1"><h2 class="title">Exam</h2>
+				<div class="date-custom">5 Jan
+				2025</div></a></div>`,
+			wantItems:   1,
+			wantNotices: 1,
+		},
+		{
+			name: "broken fields are reported, not returned",
+			html: `<div class="notification"><a href="/n/1"><h2 class="title">Exam</h2>
+				<div class="date-custom">5 Jan
+				2025</div></a></div>
+				<div class="notification"><a><h2 class="title"></h2><div class="date-custom">tomorrow</div></a></div>`,
+			wantItems:   2,
+			wantNotices: 1,
+			wantFields:  []string{"title", "date", "link"},
+		},
+		{
+			name: "unreadable date is kept undated",
+			html: `<div class="notification"><a href="/n/1"><h2 class="title">Exam</h2>
+				<div class="date-custom">31st May 2025</div></a></div>`,
+			wantItems:   1,
+			wantNotices: 1,
+			wantFields:  []string{"date"},
+		},
+		{
+			name:    "selector matches nothing",
+			html:    `<div class="notice-item">Exam</div>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := parseNoticePage(DefaultSource, []byte(tt.html))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if report.Items != tt.wantItems || len(report.Notices) != tt.wantNotices {
+				t.Errorf("items = %d, notices = %d; want %d, %d",
+					report.Items, len(report.Notices), tt.wantItems, tt.wantNotices)
+			}
+			var fields []string
+			for _, fe := range report.Errors {
+				fields = append(fields, fe.Field)
+			}
+			if len(fields) != len(tt.wantFields) {
+				t.Errorf("field errors = %v, want %v", fields, tt.wantFields)
+			}
+			for _, n := range report.Notices {
+				if n.Title == "" || n.Link == "" {
+					t.Errorf("incomplete notice returned: %+v", n)
+				}
+			}
+			if err := report.Err(); (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrNoNotices)) {
+				t.Errorf("Err() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_checkPage_dump(t *testing.T) {
+	dataPath := withTempDataDir(t)
+
+	body := []byte(`<div class="changed-layout"></div>`)
+	report, err := parseNoticePage(DefaultSource, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report.Source, report.Page = "notices", 1
+
+	if err := checkPage(report, body); !errors.Is(err, ErrNoNotices) {
+		t.Fatalf("expected ErrNoNotices, got %v", err)
+	}
+	dumps, _ := filepath.Glob(filepath.Join(dataPath, "dumps", "notices-page1-*.html"))
+	if len(dumps) != 1 {
+		t.Fatalf("expected one dump, got %v", dumps)
+	}
+	if got, _ := os.ReadFile(dumps[0]); string(got) != string(body) {
+		t.Errorf("dump content = %q", got)
+	}
+
+	// An empty page past the end of the listing is not dumped.
+	report.Page = 4
+	if err := checkPage(report, body); !errors.Is(err, ErrNoNotices) {
+		t.Fatalf("expected ErrNoNotices, got %v", err)
+	}
+	dumps, _ = filepath.Glob(filepath.Join(dataPath, "dumps", "*.html"))
+	if len(dumps) != 1 {
+		t.Errorf("expected no new dump, got %v", dumps)
+	}
+}
+
+func Test_checkPage_dumpChanges(t *testing.T) {
+	dataPath := withTempDataDir(t)
+
+	undated := []byte(`<div class="notification"><a href="/n/1"><h2 class="title">Exam</h2>
+		<div class="date-custom">31st May 2025</div></a></div>`)
+	// The same broken item, moved down as a notice is published.
+	moved := []byte(`<div class="notification"><a href="/n/2"><h2 class="title">Fees</h2>
+		<div class="date-custom">1 Jun 2025</div></a></div>` + string(undated))
+	untitled := []byte(`<div class="notification"><a href="/n/3"><h2 class="title"></h2>
+		<div class="date-custom">2 Jun 2025</div></a></div>` + string(moved))
+	clean := []byte(`<div class="notification"><a href="/n/2"><h2 class="title">Fees</h2>
+		<div class="date-custom">1 Jun 2025</div></a></div>`)
+
+	checks := []struct {
+		body     []byte
+		wantDump bool
+	}{
+		{undated, true},
+		{undated, false},
+		{moved, false},
+		{untitled, true},
+		{clean, false},
+		{undated, true},
+	}
+	for i, check := range checks {
+		before, _ := filepath.Glob(filepath.Join(dataPath, "dumps", "*.html"))
+		report, err := parseNoticePage(DefaultSource, check.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report.Source, report.Page = "notices", 1
+		if err := checkPage(report, check.body); err != nil {
+			t.Fatalf("check %d: %v", i+1, err)
+		}
+		after, _ := filepath.Glob(filepath.Join(dataPath, "dumps", "*.html"))
+		if dumped := len(after) > len(before); dumped != check.wantDump {
+			t.Errorf("check %d dumped = %v, want %v", i+1, dumped, check.wantDump)
+		}
+	}
+}
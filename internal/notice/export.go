@@ -0,0 +1,190 @@
+package notice
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Export formats understood by Export.
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// ExportFormats lists the formats understood by Export.
+var ExportFormats = []string{FormatJSON, FormatCSV, FormatMarkdown, FormatHTML}
+
+// exportedNotice is the form of a notice in exports. Internal fields such as
+// the content hash and the local copies of attachments are left out.
+type exportedNotice struct {
+	ID          string               `json:"id"`
+	Date        string               `json:"date,omitempty"`
+	Category    string               `json:"category,omitempty"`
+	Title       string               `json:"title"`
+	Description string               `json:"description,omitempty"`
+	Link        string               `json:"link"`
+	Body        string               `json:"body,omitempty"`
+	Attachments []exportedAttachment `json:"attachments,omitempty"`
+}
+
+type exportedAttachment struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func exportNotice(n Notice) exportedNotice {
+	e := exportedNotice{
+		ID:          n.ID,
+		Category:    n.Category,
+		Title:       n.Title,
+		Description: n.Desc,
+		Link:        n.Link,
+		Body:        strings.TrimSpace(n.Body),
+	}
+	if !n.Date.IsZero() {
+		e.Date = n.Date.Format(time.DateOnly)
+	}
+	for _, a := range n.Attachments {
+		e.Attachments = append(e.Attachments, exportedAttachment{Name: a.Name, URL: a.URL})
+	}
+	return e
+}
+
+// text returns the body of the notice, or its description if the body was
+// not fetched.
+func (e exportedNotice) text() string {
+	if e.Body != "" {
+		return e.Body
+	}
+	return e.Description
+}
+
+// Export writes notices to w in the given format, in the order given.
+func Export(w io.Writer, format string, notices []Notice) error {
+	exported := make([]exportedNotice, len(notices))
+	for i, n := range notices {
+		exported[i] = exportNotice(n)
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(exported)
+	case FormatCSV:
+		return exportCSV(w, exported)
+	case FormatMarkdown:
+		return exportMarkdown(w, exported)
+	case FormatHTML:
+		return exportHTMLTemplate.Execute(w, exported)
+	default:
+		return fmt.Errorf("unknown export format %q, expected one of %s", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// exportCSV writes one row per notice. The body is left out to keep rows
+// short enough for a spreadsheet; attachment links are separated by
+// newlines within their cell.
+func exportCSV(w io.Writer, notices []exportedNotice) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "date", "category", "title", "description", "link", "attachments"})
+	for _, n := range notices {
+		var links []string
+		for _, a := range n.Attachments {
+			links = append(links, a.URL)
+		}
+		_ = cw.Write([]string{n.ID, n.Date, n.Category, n.Title, n.Description, n.Link, strings.Join(links, "\n")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// markdownEscaper escapes the characters that would start markup inside a
+// line of text.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`,
+)
+
+// exportMarkdown writes each notice as a heading linking to it, followed by
+// its date and category, its text and its attachments, so it can be pasted
+// into a chat as is.
+func exportMarkdown(w io.Writer, notices []exportedNotice) error {
+	var b strings.Builder
+	for i, n := range notices {
+		if i > 0 {
+			b.WriteString("\n---\n\n")
+		}
+		fmt.Fprintf(&b, "### [%s](<%s>)\n\n", markdownEscaper.Replace(n.Title), n.Link)
+
+		var meta []string
+		if n.Date != "" {
+			meta = append(meta, n.Date)
+		}
+		if n.Category != "" {
+			meta = append(meta, markdownEscaper.Replace(n.Category))
+		}
+		if len(meta) > 0 {
+			fmt.Fprintf(&b, "*%s*\n\n", strings.Join(meta, " · "))
+		}
+
+		if text := n.text(); text != "" {
+			for _, line := range strings.Split(text, "\n") {
+				b.WriteString(markdownEscaper.Replace(strings.TrimSpace(line)) + "\n")
+			}
+			b.WriteString("\n")
+		}
+		for _, a := range n.Attachments {
+			fmt.Fprintf(&b, "- [%s](<%s>)\n", markdownEscaper.Replace(a.Name), a.URL)
+		}
+		if len(n.Attachments) > 0 {
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// exportHTMLTemplate renders a standalone page. Notice text is escaped
+// rather than copied as HTML from the site.
+var exportHTMLTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"text": exportedNotice.text,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>AIUB Notices</title>
+<style>
+body { font-family: sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+article { border-bottom: 1px solid #ddd; padding-bottom: 1rem; }
+.meta { color: #666; font-size: 0.9rem; }
+.text { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>AIUB Notices</h1>
+{{- range .}}
+<article id="{{.ID}}">
+<h2><a href="{{.Link}}">{{.Title}}</a></h2>
+<p class="meta">{{.Date}}{{if and .Date .Category}} · {{end}}{{.Category}}</p>
+{{- with text .}}
+<p class="text">{{.}}</p>
+{{- end}}
+{{- with .Attachments}}
+<ul>
+{{- range .}}
+<li><a href="{{.URL}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+</article>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,107 @@
+package notice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Export(t *testing.T) {
+	fees := Notice{
+		ID:       "0123456789abcdef",
+		Date:     time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC),
+		Category: "Notices",
+		Title:    "Fee <Payment> Deadline",
+		Desc:     "Pay fees, before the exam",
+		Link:     "https://www.aiub.edu/fees",
+		Body:     "Pay the *second* installment.\nLate payment incurs a fine.",
+		Hash:     "internal",
+		Attachments: []Attachment{
+			{Name: "schedule.pdf", URL: "https://www.aiub.edu/files/schedule.pdf", SHA256: "abc"},
+		},
+	}
+	undated := Notice{ID: "fedcba9876543210", Title: "Undated", Desc: "No body yet", Link: "https://www.aiub.edu/undated"}
+	notices := []Notice{fees, undated}
+
+	tests := []struct {
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		{
+			format: FormatJSON,
+			check: func(t *testing.T, out string) {
+				var got []map[string]any
+				if err := json.Unmarshal([]byte(out), &got); err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != 2 || got[0]["date"] != "2025-03-05" || got[0]["title"] != fees.Title {
+					t.Errorf("exported %v", got)
+				}
+				if _, ok := got[0]["Hash"]; ok || strings.Contains(out, "sha256") {
+					t.Errorf("internal fields exported: %s", out)
+				}
+				if _, ok := got[1]["date"]; ok {
+					t.Errorf("undated notice has a date: %v", got[1])
+				}
+			},
+		},
+		{
+			format: FormatCSV,
+			check: func(t *testing.T, out string) {
+				rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(rows) != 3 || rows[0][0] != "id" || rows[1][4] != fees.Desc || rows[1][6] != fees.Attachments[0].URL {
+					t.Errorf("rows = %q", rows)
+				}
+			},
+		},
+		{
+			format: FormatMarkdown,
+			check: func(t *testing.T, out string) {
+				for _, want := range []string{
+					"### [Fee \\<Payment> Deadline](<https://www.aiub.edu/fees>)",
+					"*2025-03-05 · Notices*",
+					`Pay the \*second\* installment.`,
+					"- [schedule.pdf](<https://www.aiub.edu/files/schedule.pdf>)",
+					"No body yet",
+				} {
+					if !strings.Contains(out, want) {
+						t.Errorf("markdown lacks %q:\n%s", want, out)
+					}
+				}
+			},
+		},
+		{
+			format: FormatHTML,
+			check: func(t *testing.T, out string) {
+				for _, want := range []string{
+					`<a href="https://www.aiub.edu/fees">Fee &lt;Payment&gt; Deadline</a>`,
+					"2025-03-05 · Notices",
+					`<li><a href="https://www.aiub.edu/files/schedule.pdf">schedule.pdf</a></li>`,
+				} {
+					if !strings.Contains(out, want) {
+						t.Errorf("html lacks %q:\n%s", want, out)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, tt.format, notices); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+
+	if err := Export(&bytes.Buffer{}, "xml", notices); err == nil {
+		t.Errorf("Export() accepted an unknown format")
+	}
+}
@@ -0,0 +1,210 @@
+package notice
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func init() {
+	RegisterSource(KindFeed, func(cfg SourceConfig) (Source, error) {
+		return feedSource{cfg: cfg}, nil
+	})
+}
+
+// feedSource reads notices from an RSS 2.0 or Atom feed at the source URL.
+type feedSource struct {
+	cfg SourceConfig
+}
+
+func (s feedSource) Config() SourceConfig { return s.cfg }
+
+func (s feedSource) Fetch(ctx context.Context, _ SeenSet) (FetchResult, error) {
+	result := FetchResult{Source: s.cfg.Name}
+
+	feedURL := s.cfg.URL()
+	body, validator, status, err := conditionalGet(ctx, feedURL)
+	if err != nil {
+		return result, err
+	}
+	result.Status = status
+	result.FetchedAt = time.Now()
+	if status != FetchChanged {
+		return result, nil
+	}
+
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return result, fmt.Errorf("parse feed URL: %w", err)
+	}
+	notices, err := parseFeed(s.cfg, bytes.NewReader(body), base)
+	if err != nil {
+		return result, err
+	}
+
+	result.Notices = notices
+	result.Pages = 1
+	result.validators = map[string]Validator{feedURL: validator}
+	return result, nil
+}
+
+type rssFeed struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		GUID        string `xml:"guid"`
+		Description string `xml:"description"`
+		Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		PubDate     string `xml:"pubDate"`
+		Author      string `xml:"author"`
+		Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	} `xml:"channel>item"`
+}
+
+type atomFeed struct {
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		ID        string `xml:"id"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+		Author    struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+	} `xml:"entry"`
+}
+
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// descLimit is the length of the description derived from a feed entry's
+// content when the entry has no separate summary.
+const descLimit = 300
+
+// parseFeed parses an RSS 2.0 or Atom document into notices. Relative links
+// are resolved against base.
+func parseFeed(src SourceConfig, r io.Reader, base *url.URL) ([]Notice, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read feed: %w", err)
+	}
+
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+
+	resolve := func(ref string) string {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil || base == nil {
+			return strings.TrimSpace(ref)
+		}
+		return base.ResolveReference(u).String()
+	}
+
+	var notices []Notice
+	switch root.XMLName.Local {
+	case "rss":
+		var feed rssFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, fmt.Errorf("parse RSS feed: %w", err)
+		}
+		for _, item := range feed.Items {
+			link := item.Link
+			if link == "" {
+				link = item.GUID
+			}
+			author := item.Creator
+			if author == "" {
+				author = item.Author
+			}
+			notices = append(notices, feedNotice(src, item.Title, resolve(link), item.Description, item.Content, item.PubDate, author))
+		}
+	case "feed":
+		var feed atomFeed
+		if err := xml.Unmarshal(data, &feed); err != nil {
+			return nil, fmt.Errorf("parse Atom feed: %w", err)
+		}
+		for _, entry := range feed.Entries {
+			link := entry.ID
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			date := entry.Published
+			if date == "" {
+				date = entry.Updated
+			}
+			notices = append(notices, feedNotice(src, entry.Title, resolve(link), entry.Summary, entry.Content, date, entry.Author.Name))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format %q", root.XMLName.Local)
+	}
+
+	return notices, nil
+}
+
+// feedNotice builds a notice from a feed entry. Summary and content may hold
+// HTML; the full content becomes the notice body.
+func feedNotice(src SourceConfig, title, link, summary, content, date, author string) Notice {
+	n := Notice{
+		ID:       NoticeID(link),
+		Title:    collapseSpace(title),
+		Link:     link,
+		Category: src.Category,
+		Author:   collapseSpace(author),
+	}
+
+	if t, err := src.parseDate(strings.TrimSpace(date), feedDateLayouts); err == nil {
+		n.Date = t
+		n.Posted = t
+	}
+
+	summaryText := feedText(summary)
+	if content == "" {
+		content = summary
+	}
+	if html := strings.TrimSpace(content); html != "" {
+		n.BodyHTML = html
+		n.Body = feedText(html)
+	}
+
+	n.Desc = summaryText
+	if n.Desc == "" || n.Desc == n.Body {
+		n.Desc = n.Summary(descLimit)
+	}
+
+	return n
+}
+
+// feedText converts an HTML fragment from a feed to plain text.
+func feedText(fragment string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return collapseSpace(fragment)
+	}
+	return htmlToText(doc.Find("body"))
+}
@@ -0,0 +1,92 @@
+package notice
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseFeed(t *testing.T) {
+	src := SourceConfig{Name: "cse-blog", Kind: KindFeed, Category: "CSE Blog"}.WithDefaults()
+	base, _ := url.Parse("https://blog.example.edu/feed")
+
+	tests := []struct {
+		name string
+		feed string
+		want []Notice
+	}{
+		{
+			name: "rss with content",
+			feed: `<?xml version="1.0"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel><title>Blog</title>
+<item>
+	<title> Hackathon   results </title>
+	<link>/posts/hackathon</link>
+	<description>Winners announced</description>
+	<content:encoded><![CDATA[<p>Team A won.</p><p>Team B second.</p>]]></content:encoded>
+	<pubDate>Tue, 04 Mar 2025 09:00:00 +0600</pubDate>
+	<dc:creator>CSE Club</dc:creator>
+</item>
+</channel></rss>`,
+			want: []Notice{{
+				Title:    "Hackathon results",
+				Link:     "https://blog.example.edu/posts/hackathon",
+				Desc:     "Winners announced",
+				Body:     "Team A won.\nTeam B second.",
+				Author:   "CSE Club",
+				Category: "CSE Blog",
+				Date:     time.Date(2025, 3, 4, 9, 0, 0, 0, time.FixedZone("", 6*3600)),
+			}},
+		},
+		{
+			name: "atom with summary only",
+			feed: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<entry>
+	<title>Workshop</title>
+	<link rel="alternate" href="https://blog.example.edu/workshop"/>
+	<id>tag:blog.example.edu,2025:1</id>
+	<summary type="html">&lt;b&gt;Register&lt;/b&gt; by Friday</summary>
+	<updated>2025-03-05T10:00:00Z</updated>
+	<author><name>Robotics Club</name></author>
+</entry>
+</feed>`,
+			want: []Notice{{
+				Title:    "Workshop",
+				Link:     "https://blog.example.edu/workshop",
+				Desc:     "Register by Friday",
+				Body:     "Register by Friday",
+				Author:   "Robotics Club",
+				Category: "CSE Blog",
+				Date:     time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFeed(src, strings.NewReader(tt.feed), base)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d notices, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				g := got[i]
+				if g.Title != want.Title || g.Link != want.Link || g.Desc != want.Desc ||
+					g.Body != want.Body || g.Author != want.Author || g.Category != want.Category {
+					t.Errorf("notice %d = %+v, want %+v", i, g, want)
+				}
+				if !g.Date.Equal(want.Date) {
+					t.Errorf("date = %s, want %s", g.Date, want.Date)
+				}
+			}
+		})
+	}
+
+	if _, err := parseFeed(src, strings.NewReader(`<html></html>`), base); err == nil {
+		t.Error("expected error for non-feed document")
+	}
+}
@@ -0,0 +1,287 @@
+package notice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+func init() {
+	RegisterSource(KindHTML, func(cfg SourceConfig) (Source, error) {
+		return htmlSource{cfg: cfg}, nil
+	})
+}
+
+// htmlSource scrapes a paginated HTML listing described by a SourceConfig.
+type htmlSource struct {
+	cfg SourceConfig
+}
+
+func (s htmlSource) Config() SourceConfig { return s.cfg }
+
+// Fetch returns the latest notices, following the listing's pagination
+// until it reaches a page containing a notice already present in seen.
+// When seen is empty only the first page is fetched; use Backfill to crawl
+// the whole archive. Details of new notices are fetched from their pages.
+// When the listing is unchanged, the pages of the recently seen notices it
+// last listed are still checked for edits.
+func (s htmlSource) Fetch(ctx context.Context, seen SeenSet) (FetchResult, error) {
+	src := s.cfg
+	result := FetchResult{Source: src.Name, Status: FetchChanged}
+
+	// The first page is requested conditionally; if it has not changed there
+	// is nothing new further down the listing either.
+	body, validator, status, err := conditionalGet(ctx, src.pageURL(1))
+	if err != nil {
+		return result, err
+	}
+	result.Status = status
+	result.FetchedAt = time.Now()
+	if status != FetchChanged {
+		// Edits of the body alone leave the listing unchanged.
+		result.validators = make(map[string]Validator)
+		result.Edited = recheckListed(ctx, src, seen, result.validators)
+		return result, nil
+	}
+	result.validators = map[string]Validator{src.pageURL(1): validator}
+
+	for page := 1; page <= maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		var pageNotices []Notice
+		if page == 1 {
+			report, parseErr := parseNoticePage(src, body)
+			if parseErr == nil {
+				report.URL, report.Page = src.pageURL(1), 1
+				parseErr = checkPage(report, body)
+			}
+			pageNotices, err = report.Notices, parseErr
+		} else {
+			pageNotices, err = GetNoticePage(ctx, src, page)
+		}
+		if err != nil {
+			if page == 1 {
+				return result, err
+			}
+			if !errors.Is(err, ErrNoNotices) {
+				logger.L().Warn("fetching notice page",
+					slog.Int("page", page),
+					slog.String("error", err.Error()),
+				)
+			}
+			break
+		}
+		result.Pages = page
+		result.Notices = append(result.Notices, pageNotices...)
+
+		if len(pageNotices) == 0 || seen.IsEmpty() || containsSeen(pageNotices, seen) {
+			break
+		}
+	}
+
+	fillDetails(ctx, src, result.Notices, seen, result.validators)
+	result.Withdrawn = findWithdrawn(ctx, src, result.Notices)
+
+	return result, nil
+}
+
+// fillDetails attaches detail page content to notices. Details already in
+// the cache are reused; only notices not yet in seen, or whose title or
+// description differ from the cached copy, are fetched, so unchanged known
+// notices never cause full downloads. Pages of notices first seen within
+// detailRecheckAge are checked again conditionally, to catch edits of the
+// body alone. The validators of the detail pages are added to validators.
+func fillDetails(ctx context.Context, src SourceConfig, notices []Notice, seen SeenSet, validators map[string]Validator) {
+	store, err := DefaultStore()
+	if err != nil {
+		logger.L().Warn("loading cached notice details", slog.String("error", err.Error()))
+		return
+	}
+
+	recheckSince := time.Now().Add(-detailRecheckAge)
+	for i := range notices {
+		n := &notices[i]
+		c, err := store.Notice(n.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			logger.L().Warn("loading cached notice details",
+				slog.String("id", n.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+		cached := err == nil && c.HasDetail()
+		if cached && c.Title == n.Title && c.Desc == n.Desc {
+			n.setDetail(c.detail())
+			if c.FirstSeen.Before(recheckSince) || ctx.Err() != nil {
+				continue
+			}
+			detail, validator, status, err := recheckNoticeDetail(ctx, src, n.Link)
+			if err != nil {
+				logger.L().Warn("checking notice detail",
+					slog.String("link", n.Link),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			validators[n.Link] = validator
+			if status == FetchChanged {
+				detail.Attachments = keepDownloaded(detail.Attachments, c.Attachments)
+				n.setDetail(detail)
+				downloadAttachments(ctx, n)
+			}
+			continue
+		}
+		if seen.Has(*n) && !cached {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		detail, validator, err := getNoticeDetail(ctx, src, n.Link)
+		if err != nil {
+			logger.L().Warn("fetching notice detail",
+				slog.String("link", n.Link),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		validators[n.Link] = validator
+		n.setDetail(detail)
+		downloadAttachments(ctx, n)
+	}
+}
+
+// recheckListed checks the pages of the notices last listed by src that
+// were first seen within detailRecheckAge for edits, returning the notices
+// whose content changed. The validators of the pages are added to
+// validators.
+func recheckListed(ctx context.Context, src SourceConfig, seen SeenSet, validators map[string]Validator) []Notice {
+	store, err := DefaultStore()
+	if err != nil {
+		logger.L().Warn("loading listed notices", slog.String("error", err.Error()))
+		return nil
+	}
+
+	recheckSince := time.Now().Add(-detailRecheckAge)
+	var recent []Notice
+	var hashes []string
+	for _, id := range loadListed(store)[src.Name] {
+		n, err := store.Notice(id)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				logger.L().Warn("loading listed notice",
+					slog.String("id", id),
+					slog.String("error", err.Error()),
+				)
+			}
+			continue
+		}
+		if n.HasDetail() && !n.FirstSeen.Before(recheckSince) {
+			recent = append(recent, n)
+			hashes = append(hashes, n.ContentHash())
+		}
+	}
+
+	fillDetails(ctx, src, recent, seen, validators)
+	var edited []Notice
+	for i, n := range recent {
+		if n.ContentHash() != hashes[i] {
+			edited = append(edited, n)
+		}
+	}
+	return edited
+}
+
+// GetNoticePage fetches and parses a single page of the listing of src.
+// Pages are numbered from 1. The result is not cached. Pages with parse
+// problems are dumped to the data directory; a page without any usable
+// notice yields ErrNoNotices.
+func GetNoticePage(ctx context.Context, src SourceConfig, page int) ([]Notice, error) {
+	report, body, err := InspectPage(ctx, src, page)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPage(report, body); err != nil {
+		return nil, err
+	}
+	return report.Notices, nil
+}
+
+// parseNoticePage parses a listing page of src. Items with fields that
+// cannot be parsed are reported in the errors of the result instead of
+// being returned half-filled, except that items whose date cannot be
+// parsed are returned with a zero date.
+func parseNoticePage(src SourceConfig, body []byte) (PageReport, error) {
+	report := PageReport{Source: src.Name}
+
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return report, fmt.Errorf("parse HTML: %w", err)
+	}
+
+	base, err := url.Parse(src.URL())
+	if err != nil {
+		return report, fmt.Errorf("parse source URL: %w", err)
+	}
+
+	sel := src.Selectors
+	document.Find(sel.Item).Each(func(i int, selection *goquery.Selection) {
+		report.Items++
+		var errs []FieldError
+		// incomplete is set when a field the notice cannot do without fails;
+		// a notice with an unreadable date is still announced, undated.
+		incomplete := false
+		fail := func(field, value string, err error) {
+			errs = append(errs, FieldError{Item: i + 1, Field: field, Value: value, Err: err})
+			incomplete = incomplete || field != "date"
+		}
+
+		title := collapseSpace(selection.Find(sel.Title).Text())
+		if title == "" {
+			fail("title", title, errors.New("empty"))
+		}
+		desc := collapseSpace(selection.Find(sel.Desc).Text())
+
+		dateStr := collapseSpace(selection.Find(sel.Date).Text())
+		date, err := src.parseDate(dateStr, src.DateLayouts)
+		if err != nil {
+			fail("date", dateStr, err)
+		}
+
+		// Links may be relative to the listing page or absolute.
+		link, ok := selection.Find(sel.Link).Attr(src.LinkAttr)
+		if link = strings.TrimSpace(link); !ok || link == "" {
+			fail("link", link, fmt.Errorf("missing %s attribute", src.LinkAttr))
+		} else if ref, err := url.Parse(link); err != nil {
+			fail("link", link, err)
+		} else {
+			link = base.ResolveReference(ref).String()
+		}
+
+		report.Errors = append(report.Errors, errs...)
+		if incomplete {
+			return
+		}
+		report.Notices = append(report.Notices, Notice{
+			ID:       NoticeID(link),
+			Date:     date,
+			Title:    title,
+			Desc:     desc,
+			Link:     link,
+			Category: src.Category,
+		})
+	})
+
+	return report, nil
+}
@@ -0,0 +1,98 @@
+package notice
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenPage is the stable JSON form of a parsed listing page.
+type goldenPage struct {
+	Items   int            `json:"items"`
+	Notices []goldenNotice `json:"notices"`
+	Errors  []string       `json:"errors,omitempty"`
+}
+
+type goldenNotice struct {
+	ID       string `json:"id"`
+	Date     string `json:"date"`
+	Title    string `json:"title"`
+	Desc     string `json:"desc"`
+	Link     string `json:"link"`
+	Category string `json:"category"`
+}
+
+// Test_parseNoticePage_golden parses the saved listing pages in
+// testdata/listing and compares the result with the .golden.json file next
+// to each page. Run with -update after an intended change to the parser.
+func Test_parseNoticePage_golden(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "listing", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no listing fixtures found")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			body, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := parseNoticePage(DefaultSource, body)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			got := goldenPage{Items: report.Items, Notices: []goldenNotice{}}
+			for _, n := range report.Notices {
+				got.Notices = append(got.Notices, goldenNotice{
+					ID:       n.ID,
+					Date:     n.Date.Format(time.RFC3339),
+					Title:    n.Title,
+					Desc:     n.Desc,
+					Link:     n.Link,
+					Category: n.Category,
+				})
+			}
+			for _, fe := range report.Errors {
+				got.Errors = append(got.Errors, fe.Error())
+			}
+
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(got); err != nil {
+				t.Fatal(err)
+			}
+			data := buf.Bytes()
+
+			golden := strings.TrimSuffix(page, ".html") + ".golden.json"
+			if *update {
+				if err := os.WriteFile(golden, data, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("%s mismatch\n got:\n%s\nwant:\n%s", golden, data, want)
+			}
+		})
+	}
+}
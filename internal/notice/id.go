@@ -0,0 +1,69 @@
+package notice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// idLength is the number of hex digits in a notice ID.
+const idLength = 16
+
+// trackingParams are query parameters that do not identify a page.
+var trackingParams = []string{"fbclid", "gclid", "ref"}
+
+// NoticeID returns the stable identifier of the notice at link. Links that
+// differ only in scheme, a leading "www.", letter case of the host, default
+// port, trailing slash, fragment, order of query parameters or tracking
+// parameters map to the same ID.
+func NoticeID(link string) string {
+	sum := sha256.Sum256([]byte(canonicalLink(link)))
+	return hex.EncodeToString(sum[:])[:idLength]
+}
+
+// canonicalLink returns the normalized form of link that NoticeID hashes:
+// host, path and sorted query without the scheme.
+func canonicalLink(link string) string {
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	p := path.Clean("/" + u.Path)
+	p = strings.TrimSuffix(p, "/")
+	p = (&url.URL{Path: p}).EscapedPath()
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(key, "utm_") {
+			query.Del(key)
+		}
+	}
+	for _, key := range trackingParams {
+		query.Del(key)
+	}
+
+	canonical := host + p
+	if len(query) > 0 {
+		canonical += "?" + query.Encode()
+	}
+	return canonical
+}
+
+// isNoticeID reports whether key is a notice ID rather than a link.
+func isNoticeID(key string) bool {
+	if len(key) != idLength {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
@@ -0,0 +1,43 @@
+package notice
+
+import (
+	"testing"
+)
+
+func Test_NoticeID(t *testing.T) {
+	base := NoticeID("https://www.aiub.edu/midterm-schedule")
+
+	tests := []struct {
+		name string
+		link string
+		same bool
+	}{
+		{name: "trailing slash", link: "https://www.aiub.edu/midterm-schedule/", same: true},
+		{name: "http scheme", link: "http://www.aiub.edu/midterm-schedule", same: true},
+		{name: "without www", link: "https://aiub.edu/midterm-schedule", same: true},
+		{name: "host case and default port", link: "https://WWW.AIUB.EDU:443/midterm-schedule", same: true},
+		{name: "fragment", link: "https://www.aiub.edu/midterm-schedule#top", same: true},
+		{name: "tracking parameters", link: "https://www.aiub.edu/midterm-schedule?utm_source=fb&fbclid=x", same: true},
+		{name: "dot segments", link: "https://www.aiub.edu/category/../midterm-schedule", same: true},
+		{name: "surrounding space", link: "  https://www.aiub.edu/midterm-schedule ", same: true},
+		{name: "different path", link: "https://www.aiub.edu/final-schedule", same: false},
+		{name: "path case", link: "https://www.aiub.edu/Midterm-Schedule", same: false},
+		{name: "identifying query", link: "https://www.aiub.edu/midterm-schedule?id=2", same: false},
+		{name: "other host", link: "https://portal.aiub.edu/midterm-schedule", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NoticeID(tt.link) == base; got != tt.same {
+				t.Errorf("NoticeID(%q) same = %v, want %v (canonical %q)", tt.link, got, tt.same, canonicalLink(tt.link))
+			}
+		})
+	}
+
+	if !isNoticeID(base) || isNoticeID("https://www.aiub.edu/x") {
+		t.Errorf("isNoticeID misclassifies IDs and links")
+	}
+	if a, b := NoticeID("https://www.aiub.edu/n?b=2&a=1"), NoticeID("https://www.aiub.edu/n?a=1&b=2"); a != b {
+		t.Errorf("query order changes the ID: %s != %s", a, b)
+	}
+}
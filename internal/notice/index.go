@@ -0,0 +1,155 @@
+package notice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketSearchIndex is the inverted index of the notices. Each key is a
+// term and a notice ID separated by a zero byte, so the notices containing
+// a term are found by a prefix scan; the value is the posting of the term
+// in the notice.
+var bucketSearchIndex = []byte("search_index")
+
+func indexKey(term, id string) []byte {
+	key := make([]byte, 0, len(term)+1+len(id))
+	key = append(key, term...)
+	key = append(key, 0)
+	return append(key, id...)
+}
+
+// indexNotice replaces the postings of old, if given, by those of n.
+func indexNotice(tx *bolt.Tx, old *Notice, n Notice) error {
+	if old != nil && indexedFields(*old) == indexedFields(n) {
+		return nil
+	}
+	if old != nil {
+		if err := unindexNotice(tx, *old); err != nil {
+			return err
+		}
+	}
+
+	index := tx.Bucket(bucketSearchIndex)
+	for term, p := range postings(n) {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("encode posting: %w", err)
+		}
+		if err := index.Put(indexKey(term, n.ID), data); err != nil {
+			return fmt.Errorf("index notice %s: %w", n.ID, err)
+		}
+	}
+	return nil
+}
+
+// unindexNotice removes the postings of n from the index.
+func unindexNotice(tx *bolt.Tx, n Notice) error {
+	index := tx.Bucket(bucketSearchIndex)
+	for term := range postings(n) {
+		if err := index.Delete(indexKey(term, n.ID)); err != nil {
+			return fmt.Errorf("unindex notice %s: %w", n.ID, err)
+		}
+	}
+	return nil
+}
+
+// indexAll indexes every stored notice; it builds the index of stores
+// written before search existed.
+func indexAll(tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(bucketSearchIndex); err != nil {
+		return fmt.Errorf("create bucket %s: %w", bucketSearchIndex, err)
+	}
+	return tx.Bucket(bucketNotices).ForEach(func(k, v []byte) error {
+		var n Notice
+		if err := json.Unmarshal(v, &n); err != nil {
+			return fmt.Errorf("decode notice %s: %w", k, err)
+		}
+		return indexNotice(tx, nil, n)
+	})
+}
+
+// termPostings returns the postings of term, by notice ID.
+func termPostings(index *bolt.Bucket, term string) (map[string]posting, error) {
+	prefix := indexKey(term, "")
+	found := make(map[string]posting)
+	c := index.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var p posting
+		if err := json.Unmarshal(v, &p); err != nil {
+			return nil, fmt.Errorf("decode posting of %q: %w", term, err)
+		}
+		found[string(k[len(prefix):])] = p
+	}
+	return found, nil
+}
+
+func (s *BoltStore) Search(q Query) ([]SearchResult, error) {
+	words := q.words()
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	var results []SearchResult
+	err := s.view(func(tx *bolt.Tx) error {
+		index := tx.Bucket(bucketSearchIndex)
+		if index == nil {
+			return nil
+		}
+		total := float64(tx.Bucket(bucketNotices).Stats().KeyN)
+
+		// found holds the postings of each word in the notices containing
+		// all words so far.
+		var found map[string]map[string]posting
+		idf := make(map[string]float64, len(words))
+		for _, w := range words {
+			byID, err := termPostings(index, w)
+			if err != nil {
+				return err
+			}
+			if len(byID) == 0 {
+				found = nil
+				break
+			}
+			idf[w] = math.Log(1 + total/float64(len(byID)))
+
+			if found == nil {
+				found = make(map[string]map[string]posting, len(byID))
+				for id, p := range byID {
+					found[id] = map[string]posting{w: p}
+				}
+				continue
+			}
+			for id, matched := range found {
+				if p, ok := byID[id]; ok {
+					matched[w] = p
+				} else {
+					delete(found, id)
+				}
+			}
+		}
+
+		for id, matched := range found {
+			n, ok, err := getNotice(tx, id)
+			if err != nil {
+				return err
+			}
+			if !ok || !q.accepts(n) {
+				continue
+			}
+			rank, ok := score(q, matched, idf)
+			if !ok {
+				continue
+			}
+			result := SearchResult{Notice: n, Score: rank}
+			result.Snippet, result.Highlights = snippet(n, q)
+			results = append(results, result)
+		}
+		return nil
+	})
+	rankResults(results)
+	return results, err
+}
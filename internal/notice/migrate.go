@@ -0,0 +1,191 @@
+package notice
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+// Files written by versions that kept their state in JSON files.
+const (
+	legacyNoticesFile    = "notices.json"
+	legacySeenFile       = "seen_notices.json"
+	legacyValidators     = "validators.json"
+	legacyBackfillFile   = "backfill_state.json"
+	legacyRevisionsDir   = "revisions"
+	legacyImportedSuffix = ".imported"
+)
+
+// Metadata keys of the store.
+const (
+	metaValidators = "validators"
+	metaBackfill   = "backfill"
+	metaPruned     = "pruned"
+	metaListed     = "listed"
+	metaPrimed     = "primed"
+	metaDiagnosed  = "diagnosed"
+)
+
+// importJSONState imports the JSON state files found in dir into store in a
+// single transaction. Imported files are renamed with an ".imported" suffix
+// so they are kept as a backup but not imported again.
+func importJSONState(dir string, store *BoltStore) error {
+	var notices []Notice
+	var seen map[string]struct{}
+	var validators map[string]Validator
+	var backfill map[string]BackfillState
+
+	var imported []string
+	load := func(name string, v any) error {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("decode %s: %w", name, err)
+		}
+		imported = append(imported, path)
+		return nil
+	}
+	for name, v := range map[string]any{
+		legacyNoticesFile:  &notices,
+		legacySeenFile:     &seen,
+		legacyValidators:   &validators,
+		legacyBackfillFile: &backfill,
+	} {
+		if err := load(name, v); err != nil {
+			return err
+		}
+	}
+
+	revisions := make(map[string][]Revision)
+	entries, _ := os.ReadDir(filepath.Join(dir, legacyRevisionsDir))
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !isNoticeID(id) {
+			continue
+		}
+		var r []Revision
+		if err := load(filepath.Join(legacyRevisionsDir, e.Name()), &r); err != nil {
+			return err
+		}
+		revisions[id] = r
+	}
+
+	if len(imported) == 0 {
+		return nil
+	}
+
+	for i := range notices {
+		fillLegacyFields(&notices[i])
+	}
+	seen = migrateSeenNotices(seen)
+
+	err := store.update(func(tx *bolt.Tx) error {
+		if err := putNotices(tx, notices); err != nil {
+			return err
+		}
+		for id := range seen {
+			if err := tx.Bucket(bucketSeen).Put([]byte(id), []byte{}); err != nil {
+				return err
+			}
+		}
+		// Older versions did not track reading; count the notices they
+		// announced as read rather than flag the whole archive unread.
+		now, err := time.Now().MarshalBinary()
+		if err != nil {
+			return err
+		}
+		for _, n := range notices {
+			if _, ok := seen[n.ID]; !ok {
+				continue
+			}
+			if err := tx.Bucket(bucketRead).Put([]byte(n.ID), now); err != nil {
+				return err
+			}
+		}
+		for id, r := range revisions {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(bucketRevisions).Put([]byte(id), data); err != nil {
+				return err
+			}
+		}
+		meta := make(map[string]any)
+		if validators != nil {
+			meta[metaValidators] = validators
+		}
+		if backfill != nil {
+			meta[metaBackfill] = backfill
+		}
+		for key, v := range meta {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket(bucketMeta).Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range imported {
+		if err := os.Rename(path, path+legacyImportedSuffix); err != nil {
+			logger.L().Warn("renaming imported state file", slog.String("path", path), slog.String("error", err.Error()))
+		}
+	}
+	logger.L().Info("imported JSON state into the store",
+		slog.String("store", store.Path()),
+		slog.Int("notices", len(notices)),
+		slog.Int("seen", len(seen)),
+		slog.Int("revised", len(revisions)),
+	)
+	return nil
+}
+
+// fillLegacyFields sets the fields that caches written by older versions
+// lack.
+func fillLegacyFields(n *Notice) {
+	if n.ID == "" {
+		n.ID = NoticeID(n.Link)
+	}
+	if n.Hash == "" {
+		n.Hash = n.ContentHash()
+	}
+	if n.FirstSeen.IsZero() {
+		// cached before sightings were recorded
+		n.FirstSeen = n.Date
+	}
+}
+
+// migrateSeenNotices replaces the links that older versions used as keys of
+// the seen notices with notice IDs.
+func migrateSeenNotices(seen map[string]struct{}) map[string]struct{} {
+	migrated := make(map[string]struct{}, len(seen))
+	for key := range seen {
+		if !isNoticeID(key) {
+			key = NoticeID(key)
+		}
+		migrated[key] = struct{}{}
+	}
+	return migrated
+}
@@ -2,113 +2,302 @@
 package notice
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 	_ "time/tzdata"
 
-	"github.com/PuerkitoBio/goquery"
-
+	"github.com/AtifChy/aiub-notice/internal/common"
 	"github.com/AtifChy/aiub-notice/internal/logger"
 )
 
+const (
+	// maxPages bounds how far an HTML source follows the pagination when looking
+	// for the first already seen notice.
+	maxPages = 10
+)
+
 type Notice struct {
-	Date  time.Time
-	Title string
-	Desc  string
-	Link  string
+	// ID identifies the notice independently of how its link is spelled;
+	// see NoticeID.
+	ID   string
+	Date time.Time
+	// Hash is the ContentHash of the notice when it was last cached.
+	Hash     string
+	Title    string
+	Desc     string
+	Link     string
+	Category string
+
+	// Fields below are scraped from the notice's own page and are empty
+	// until its details have been fetched.
+	Body        string
+	BodyHTML    string
+	Posted      time.Time
+	Author      string
+	Attachments []Attachment
+
+	// FirstSeen and LastSeen are when the notice was first and most
+	// recently found on its listing. Checks finding the listing unchanged
+	// update LastSeen too, at most every lastSeenInterval.
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// WithdrawnAt is when the notice was found to have been taken down
+	// before aging off the listing; zero while it is still published.
+	WithdrawnAt time.Time
+}
+
+// Withdrawn reports whether the notice was taken down from the site.
+func (n Notice) Withdrawn() bool {
+	return !n.WithdrawnAt.IsZero()
 }
 
-func GetNotices() ([]Notice, error) {
-	var notices []Notice
-	const maxRetries = 5
+// category returns the category of the notice. Notices cached before
+// categories existed belong to the default source.
+func (n Notice) category() string {
+	if n.Category == "" {
+		return DefaultSource.Category
+	}
+	return n.Category
+}
 
-	rootURL := "https://www.aiub.edu"
-	noticeURL := rootURL + "/category/notices"
+// HasDetail reports whether the notice's detail page has been scraped.
+func (n Notice) HasDetail() bool {
+	return n.Body != "" || n.BodyHTML != ""
+}
 
-	response, err := httpGetWithRetry(noticeURL, maxRetries)
-	if err != nil {
-		return nil, err
+// DateText returns the listing date of the notice for display, or
+// "undated" if the date on the listing could not be parsed.
+func (n Notice) DateText() string {
+	if n.Date.IsZero() {
+		return "undated"
 	}
-	defer func() { _ = response.Body.Close() }()
+	return n.Date.Format("02 Jan 2006")
+}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received status code %d", response.StatusCode)
+// Summary returns the best available short text for the notice: the start
+// of the full body when known, otherwise the listing description.
+func (n Notice) Summary(limit int) string {
+	text := n.Desc
+	if n.Body != "" {
+		text = strings.Join(strings.Fields(n.Body), " ")
+	}
+	if limit > 0 && len([]rune(text)) > limit {
+		text = strings.TrimSpace(string([]rune(text)[:limit-1])) + "…"
 	}
+	return text
+}
 
-	document, err := goquery.NewDocumentFromReader(response.Body)
-	if err != nil {
-		return nil, fmt.Errorf("parse HTML: %w", err)
+func (n Notice) detail() Detail {
+	return Detail{
+		Body:        n.Body,
+		BodyHTML:    n.BodyHTML,
+		Posted:      n.Posted,
+		Author:      n.Author,
+		Attachments: n.Attachments,
 	}
+}
+
+func (n *Notice) setDetail(d Detail) {
+	n.Body = d.Body
+	n.BodyHTML = d.BodyHTML
+	n.Posted = d.Posted
+	n.Author = d.Author
+	n.Attachments = d.Attachments
+}
+
+// SeenSet holds the notices that were already announced or deliberately
+// skipped.
+type SeenSet struct {
+	// IDs are the IDs of the seen notices.
+	IDs map[string]struct{}
+	// PrunedBefore holds for each category the date of its newest notice
+	// pruned from the store. Notices of the category dated before it count
+	// as seen even though their IDs were dropped, so pruned notices are not
+	// announced again if they reappear. The dates are kept per category so
+	// that pruning a busy category does not hide older-dated notices of a
+	// quiet one, or of a category enabled later.
+	PrunedBefore map[string]time.Time
+}
 
-	loc, err := time.LoadLocation("Asia/Dhaka")
+// LoadSeen returns the seen set kept in store.
+func LoadSeen(store Store) (SeenSet, error) {
+	ids, err := store.Seen()
+	if err != nil {
+		return SeenSet{}, err
+	}
+	pruned, err := store.PruneDates()
 	if err != nil {
-		return nil, fmt.Errorf("loading location: %w", err)
+		return SeenSet{}, err
 	}
+	return SeenSet{IDs: ids, PrunedBefore: pruned}, nil
+}
 
-	document.Find("div.notification").Each(func(_ int, selection *goquery.Selection) {
-		title := strings.TrimSpace(selection.Find("h2.title").Text())
-		desc := strings.TrimSpace(selection.Find("p.desc").Text())
+// IsEmpty reports whether nothing was ever seen.
+func (s SeenSet) IsEmpty() bool {
+	return len(s.IDs) == 0 && len(s.PrunedBefore) == 0
+}
 
-		dateStr := selection.Find("div.date-custom").Text()
-		dateStr = strings.TrimSpace(dateStr)
-		re := regexp.MustCompile(`[\s\n]+`)
-		dateStr = re.ReplaceAllString(dateStr, " ")
+// Has reports whether n was seen.
+func (s SeenSet) Has(n Notice) bool {
+	if _, ok := s.IDs[n.ID]; ok {
+		return true
+	}
+	return !n.Date.IsZero() && n.Date.Before(s.PrunedBefore[n.category()])
+}
 
-		date, err := time.ParseInLocation("2 Jan 2006", dateStr, loc)
+// Add records the notice with the given ID as seen.
+func (s *SeenSet) Add(id string) {
+	if s.IDs == nil {
+		s.IDs = make(map[string]struct{})
+	}
+	s.IDs[id] = struct{}{}
+}
+
+// PrimedCategories returns the categories whose notices were recorded as
+// seen when first fetched. They are kept apart from the notices, so a
+// category stays primed when all its notices are pruned. Stores written
+// before they were kept take the categories of their cached notices.
+func PrimedCategories(store Store) (map[string]struct{}, error) {
+	primed := make(map[string]struct{})
+	var categories []string
+	found, err := store.Meta(metaPrimed, &categories)
+	if err != nil {
+		return primed, fmt.Errorf("load primed categories: %w", err)
+	}
+	if !found {
+		cached, err := store.Notices()
 		if err != nil {
-			logger.L().Warn("parsing date",
-				slog.String("date", dateStr),
-				slog.String("error", err.Error()),
-			)
+			return primed, fmt.Errorf("load cached notices: %w", err)
 		}
+		for _, n := range cached {
+			category := n.category()
+			if _, ok := primed[category]; !ok {
+				categories = append(categories, category)
+				primed[category] = struct{}{}
+			}
+		}
+		return primed, MarkPrimed(store, categories...)
+	}
+	for _, c := range categories {
+		primed[c] = struct{}{}
+	}
+	return primed, nil
+}
 
-		link, _ := selection.Find("a").Attr("href")
-		link = rootURL + link
-
-		notices = append(notices, Notice{
-			Date:  date,
-			Title: title,
-			Desc:  desc,
-			Link:  link,
-		})
-	})
+// MarkPrimed records the given categories as primed.
+func MarkPrimed(store Store, categories ...string) error {
+	var primed []string
+	if _, err := store.Meta(metaPrimed, &primed); err != nil {
+		return fmt.Errorf("load primed categories: %w", err)
+	}
+	primed = append(primed, categories...)
+	sort.Strings(primed)
+	primed = slices.Compact(primed)
+	if err := store.SetMeta(metaPrimed, primed); err != nil {
+		return fmt.Errorf("save primed categories: %w", err)
+	}
+	return nil
+}
 
-	if err := storeCachedNotices(notices); err != nil {
-		logger.L().Warn("caching notices", slog.String("error", err.Error()))
+func containsSeen(notices []Notice, seen SeenSet) bool {
+	for _, n := range notices {
+		if seen.Has(n) {
+			return true
+		}
 	}
+	return false
+}
 
-	return notices, nil
+// sleep waits for d or until ctx is done, whichever comes first.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-var sleep = time.Sleep
+func httpGetWithRetry(ctx context.Context, url string, policy RetryPolicy) (*http.Response, error) {
+	return httpGetWithHeaders(ctx, url, nil, policy)
+}
 
-func httpGetWithRetry(url string, maxRetries int) (*http.Response, error) {
+// httpGetWithHeaders is httpGetWithRetry with extra request headers.
+func httpGetWithHeaders(ctx context.Context, url string, header http.Header, policy RetryPolicy) (*http.Response, error) {
 	var response *http.Response
 	var err error
 
-	client := http.Client{
-		Timeout: 5 * time.Second,
-	}
+	client := common.HTTPClient()
 
-	for i := range maxRetries {
-		response, err = client.Get(url)
-		if err == nil {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		var request *http.Request
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		for key, values := range header {
+			request.Header[key] = values
+		}
+
+		response, err = client.Do(request)
+		if ctx.Err() != nil {
+			if response != nil {
+				_ = response.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		if err == nil && !retryable(response.StatusCode) {
 			return response, nil
 		}
 
-		waitTime := time.Duration((i+1)*2) * time.Second
+		waitTime := policy.backoff(attempt)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = response.Status
+			if after, ok := retryAfter(response.Header.Get("Retry-After"), time.Now()); ok {
+				waitTime = after
+			}
+		}
+
+		outOfBudget := policy.Budget > 0 && time.Since(start)+waitTime > policy.Budget.D()
+		if attempt >= policy.MaxAttempts || outOfBudget {
+			logger.L().Warn(
+				"HTTP GET giving up",
+				slog.Int("attempt", attempt),
+				slog.String("reason", reason),
+				slog.Bool("budget_exhausted", outOfBudget),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("all retries failed: %w", err)
+			}
+			// Hand the last response to the caller so it can report the status.
+			return response, nil
+		}
+		if response != nil {
+			_ = response.Body.Close()
+		}
+
 		logger.L().Warn(
 			"HTTP GET attempt failed",
-			slog.Int("attempt", i+1),
-			slog.String("error", err.Error()),
+			slog.Int("attempt", attempt),
+			slog.String("reason", reason),
 			slog.String("wait", waitTime.String()),
 		)
-		sleep(waitTime)
+		if err := sleep(ctx, waitTime); err != nil {
+			return nil, err
+		}
 	}
-
-	return nil, fmt.Errorf("all retries failed: %w", err)
 }
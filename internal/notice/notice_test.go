@@ -1,6 +1,8 @@
 package notice
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -11,7 +13,7 @@ import (
 
 func Test_httpGetWithRetry(t *testing.T) {
 	origSleep := sleep
-	sleep = func(time.Duration) {}
+	sleep = func(context.Context, time.Duration) error { return nil }
 	defer func() { sleep = origSleep }()
 
 	tests := []struct {
@@ -89,8 +91,71 @@ func Test_httpGetWithRetry(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			server, maxRetries := tt.setup()
 			defer server.Close()
-			resp, err := httpGetWithRetry(server.URL, maxRetries)
+			resp, err := httpGetWithRetry(context.Background(), server.URL, RetryPolicy{MaxAttempts: maxRetries})
 			tt.validate(t, resp, err)
 		})
 	}
 }
+
+func Test_httpGetWithRetry_cancel(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		hj, ok := w.(http.Hijacker)
+		if ok {
+			conn, _, _ := hj.Hijack()
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	_, err := httpGetWithRetry(ctx, server.URL, RetryPolicy{MaxAttempts: 5})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled request took %s", elapsed)
+	}
+	if n := atomic.LoadInt32(&attempts); n > 1 {
+		t.Errorf("expected no retries after cancellation, got %d attempts", n)
+	}
+}
+
+func Test_sleep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if err := sleep(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("sleep ignored cancellation for %s", elapsed)
+	}
+}
+
+func Test_Notice_DateText(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want string
+	}{
+		{name: "dated", date: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), want: "01 Mar 2025"},
+		{name: "undated", want: "undated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Notice{Date: tt.date}).DateText(); got != tt.want {
+				t.Errorf("DateText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package notice
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+// storeBackups is the number of backups of the store that are kept. The
+// newest is path.1, the oldest path.<storeBackups>.
+const storeBackups = 3
+
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
+
+// Backup saves a consistent copy of the store next to it, rotating the
+// older copies. Nothing is saved while the store does not exist.
+func (s *BoltStore) Backup() error {
+	if !storeExists(s.path) {
+		return nil
+	}
+
+	tmp := s.path + ".backup"
+	err := s.view(func(tx *bolt.Tx) error {
+		return tx.CopyFile(tmp, 0o600)
+	})
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("back up store: %w", err)
+	}
+	if err := verifyStore(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("back up store: copy is unusable: %w", err)
+	}
+
+	for i := storeBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupPath(s.path, i), backupPath(s.path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotate store backups: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, backupPath(s.path, 1)); err != nil {
+		return fmt.Errorf("back up store: %w", err)
+	}
+	return nil
+}
+
+// verifyStore opens the database file at path and checks the consistency
+// of all its pages.
+func verifyStore(path string) (err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		// bbolt would silently initialize an empty file
+		return errors.New("database file is empty")
+	}
+
+	// A damaged page can make bbolt panic instead of returning an error.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("database is corrupt: %v", r)
+		}
+	}()
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: storeLockTimeout, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	return db.View(func(tx *bolt.Tx) error {
+		var errs []error
+		for err := range tx.Check() {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// recoverStore checks the store and replaces it by its newest usable backup
+// if it is damaged. The damaged file is kept aside for inspection. Recovery
+// is logged as an error, and fails if no backup can be restored: starting
+// over silently would forget which notices were already announced.
+func recoverStore(s *BoltStore) error {
+	if !storeExists(s.path) {
+		return nil
+	}
+	damage := verifyStore(s.path)
+	if damage == nil {
+		return nil
+	}
+
+	corrupt := fmt.Sprintf("%s.corrupt-%s", s.path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(s.path, corrupt); err != nil {
+		return fmt.Errorf("store %s is damaged (%w) and could not be moved aside: %w", s.path, damage, err)
+	}
+	logger.L().Error("store is damaged, restoring from backup",
+		slog.String("store", s.path),
+		slog.String("moved_to", corrupt),
+		slog.String("error", damage.Error()),
+	)
+
+	for i := 1; i <= storeBackups; i++ {
+		backup := backupPath(s.path, i)
+		info, err := os.Stat(backup)
+		if err != nil {
+			continue
+		}
+		if err := verifyStore(backup); err != nil {
+			logger.L().Error("skipping damaged store backup",
+				slog.String("backup", backup),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		data, err := os.ReadFile(backup)
+		if err != nil {
+			continue
+		}
+		if err := common.WriteFileAtomic(s.path, data, 0o600); err != nil {
+			return fmt.Errorf("restore store from %s: %w", backup, err)
+		}
+		logger.L().Error("restored store from backup; changes since the backup are lost",
+			slog.String("store", s.path),
+			slog.String("backup", backup),
+			slog.Time("backup_time", info.ModTime()),
+		)
+		return nil
+	}
+
+	return fmt.Errorf("store %s is damaged (%w) and no usable backup exists; "+
+		"it was moved to %s and the next run starts with an empty store", s.path, damage, corrupt)
+}
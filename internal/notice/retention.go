@@ -0,0 +1,142 @@
+package notice
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+// RetentionPolicy bounds how many notices the store keeps. Notices beyond
+// either limit are pruned; a zero limit does not apply. Starred notices and
+// notices with a note are always kept and do not count against MaxNotices.
+type RetentionPolicy struct {
+	// MaxAge prunes notices dated longer ago than this.
+	MaxAge common.Duration `json:"max_age"`
+	// MaxNotices prunes all but this many of the newest notices.
+	MaxNotices int `json:"max_notices"`
+}
+
+// IsZero reports whether the policy keeps every notice.
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxAge == 0 && p.MaxNotices == 0
+}
+
+// Validate reports whether the policy is usable.
+func (p RetentionPolicy) Validate() error {
+	if p.MaxAge < 0 {
+		return fmt.Errorf("retention: max_age must not be negative")
+	}
+	if p.MaxNotices < 0 {
+		return fmt.Errorf("retention: max_notices must not be negative")
+	}
+	return nil
+}
+
+// PlanPrune returns the notices in store that p prunes at now, newest first.
+func PlanPrune(store Store, p RetentionPolicy, now time.Time) ([]Notice, error) {
+	if p.IsZero() {
+		return nil, nil
+	}
+	notices, err := store.Notices()
+	if err != nil {
+		return nil, fmt.Errorf("load notices: %w", err)
+	}
+	annotations, err := store.Annotations()
+	if err != nil {
+		return nil, fmt.Errorf("load annotations: %w", err)
+	}
+
+	cutoff := now.Add(-p.MaxAge.D())
+	var pruned []Notice
+	kept := 0
+	for _, n := range notices {
+		if _, annotated := annotations[n.ID]; annotated {
+			continue
+		}
+		// The age of an undated notice is unknown; only the count limit
+		// applies to it.
+		tooOld := p.MaxAge > 0 && !n.Date.IsZero() && n.Date.Before(cutoff)
+		tooMany := p.MaxNotices > 0 && kept >= p.MaxNotices
+		if tooOld || tooMany {
+			pruned = append(pruned, n)
+			continue
+		}
+		kept++
+	}
+	return pruned, nil
+}
+
+// Prune deletes the notices that p prunes at now from store and returns
+// them. Pruned notices are not announced again if they reappear on the
+// site.
+func Prune(store Store, p RetentionPolicy, now time.Time) ([]Notice, error) {
+	pruned, err := PlanPrune(store, p, now)
+	if err != nil || len(pruned) == 0 {
+		return nil, err
+	}
+	ids := make([]string, len(pruned))
+	for i, n := range pruned {
+		ids[i] = n.ID
+	}
+	if err := store.PruneNotices(ids...); err != nil {
+		return nil, fmt.Errorf("prune notices: %w", err)
+	}
+	return pruned, nil
+}
+
+// compactTxSize bounds the size of each transaction while compacting.
+const compactTxSize = 4 << 20
+
+// Compact rewrites the store into a new file. bbolt reuses the pages freed
+// by deleted data but never shrinks the file, so pruning alone does not
+// save disk space.
+func (s *BoltStore) Compact() error {
+	if !storeExists(s.path) {
+		return nil
+	}
+	src, err := s.open()
+	if err != nil {
+		return err
+	}
+	closed := false
+	defer func() {
+		if !closed {
+			_ = src.Close()
+		}
+	}()
+
+	tmp := s.path + ".compact"
+	_ = os.Remove(tmp)
+	dst, err := bolt.Open(tmp, 0o600, nil)
+	if err != nil {
+		return fmt.Errorf("compact store: %w", err)
+	}
+	err = bolt.Compact(dst, src, compactTxSize)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = verifyStore(tmp)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("compact store: %w", err)
+	}
+
+	// The store stays locked while it is replaced so that no other process
+	// writes to the old file; see open. Windows does not replace open
+	// files, so there the lock is released first.
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = src.Close()
+		closed = true
+		if err := os.Rename(tmp, s.path); err != nil {
+			_ = os.Remove(tmp)
+			return fmt.Errorf("compact store: %w", err)
+		}
+	}
+	return nil
+}
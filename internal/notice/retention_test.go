@@ -0,0 +1,292 @@
+package notice
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+func Test_PlanPrune(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	now := day(30)
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []string
+	}{
+		{name: "zero policy keeps everything"},
+		{
+			name:   "by age",
+			policy: RetentionPolicy{MaxAge: common.Duration(10 * 24 * time.Hour)},
+			want:   []string{"/d10", "/d5"},
+		},
+		{
+			name:   "by count",
+			policy: RetentionPolicy{MaxNotices: 2},
+			want:   []string{"/d10", "/d5", "/undated"},
+		},
+		{
+			name:   "both limits",
+			policy: RetentionPolicy{MaxAge: common.Duration(3 * 24 * time.Hour), MaxNotices: 2},
+			want:   []string{"/d25", "/d10", "/d5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+			var notices []Notice
+			for _, d := range []int{29, 25, 20, 10, 5} {
+				notices = append(notices, testNotice(fmt.Sprintf("/d%d", d), day(d)))
+			}
+			notices = append(notices, testNotice("/undated", time.Time{}))
+			if err := store.PutNotices(notices...); err != nil {
+				t.Fatal(err)
+			}
+			// annotated notices are kept and not counted
+			if err := store.SetAnnotation(NoticeID("https://www.aiub.edu/d20"), Annotation{StarredAt: now}); err != nil {
+				t.Fatal(err)
+			}
+
+			pruned, err := PlanPrune(store, tt.policy, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, n := range pruned {
+				got = append(got, n.Title)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("PlanPrune() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testNotice(path string, date time.Time) Notice {
+	link := "https://www.aiub.edu" + path
+	return Notice{ID: NoticeID(link), Link: link, Title: path, Date: date}
+}
+
+func Test_Prune_keepsSeen(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	older, sameDay, newest := testNotice("/older", day(1)), testNotice("/same-day", day(2)), testNotice("/newest", day(9))
+	undated := testNotice("/undated", time.Time{})
+	all := []Notice{older, sameDay, newest, undated}
+	if err := store.PutNotices(all...); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range all {
+		if err := store.MarkSeen(n.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetRead(day(9), older.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := Prune(store, RetentionPolicy{MaxNotices: 1}, day(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 3 {
+		t.Fatalf("Prune() pruned %d notices, want 3", len(pruned))
+	}
+	if count, _ := store.CountNotices(); count != 1 {
+		t.Errorf("CountNotices() = %d after pruning", count)
+	}
+	if read, _ := store.Read(); len(read) != 0 {
+		t.Errorf("Read() = %v after pruning", read)
+	}
+	if results, _ := store.Search(ParseQuery("older")); len(results) != 0 {
+		t.Errorf("Search() found a pruned notice")
+	}
+
+	seen, err := LoadSeen(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]time.Time{DefaultSource.Category: day(2)}; !reflect.DeepEqual(seen.PrunedBefore, want) {
+		t.Errorf("PrunedBefore = %v, want %v", seen.PrunedBefore, want)
+	}
+	if _, ok := seen.IDs[older.ID]; ok {
+		t.Errorf("seen entry of a notice covered by PrunedBefore was kept")
+	}
+	for _, n := range all {
+		if !seen.Has(n) {
+			t.Errorf("pruned notice %s reappearing is not seen", n.Title)
+		}
+	}
+	if later := testNotice("/later-same-day", day(2)); seen.Has(later) {
+		t.Errorf("new notice dated on PrunedBefore is seen")
+	}
+	if seen.Has(testNotice("/undated-new", time.Time{})) {
+		t.Errorf("new undated notice is seen")
+	}
+}
+
+func Test_Prune_perCategory(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	categorized := func(path, category string, date time.Time) Notice {
+		n := testNotice(path, date)
+		n.Category = category
+		return n
+	}
+
+	// The busy notices category loses its older notices to the count limit.
+	var notices []Notice
+	for d := 3; d <= 9; d++ {
+		notices = append(notices, categorized(fmt.Sprintf("/notice-%d", d), "Notices", day(d)))
+	}
+	quiet := categorized("/news-1", "News", day(1))
+	notices = append(notices, quiet)
+	if err := store.PutNotices(notices...); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range notices {
+		if err := store.MarkSeen(n.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Prune(store, RetentionPolicy{MaxNotices: 2}, day(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	seen, err := LoadSeen(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Time{"Notices": day(7), "News": day(1)}
+	if !reflect.DeepEqual(seen.PrunedBefore, want) {
+		t.Errorf("PrunedBefore = %v, want %v", seen.PrunedBefore, want)
+	}
+
+	tests := []struct {
+		name     string
+		notice   Notice
+		wantSeen bool
+	}{
+		{name: "pruned notice reappearing", notice: notices[0], wantSeen: true},
+		{name: "new notice of a quiet category dated before the boundary of a busy one", notice: categorized("/news-5", "News", day(5))},
+		{name: "backlog of a category enabled later", notice: categorized("/event-2", "Events", day(2))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seen.Has(tt.notice); got != tt.wantSeen {
+				t.Errorf("Has(%s) = %v, want %v", tt.notice.Link, got, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func Test_LoadSeen_legacyPruneDate(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	// a store written when a single prune date covered every category
+	if err := store.SetMeta(metaPruned, day(5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := MarkPrimed(store, "Notices"); err != nil {
+		t.Fatal(err)
+	}
+
+	seen, err := LoadSeen(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]time.Time{"Notices": day(5)}; !reflect.DeepEqual(seen.PrunedBefore, want) {
+		t.Errorf("PrunedBefore = %v, want the date to apply to the primed categories %v", seen.PrunedBefore, want)
+	}
+	later := testNotice("/events", day(1))
+	later.Category = "Events"
+	if seen.Has(later) {
+		t.Errorf("notice of a category primed after the prune is seen")
+	}
+}
+
+func Test_PrimedCategories_afterPrune(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	// a store written before primed categories were kept
+	news := testNotice("/news", day(1))
+	news.Category = "News"
+	legacy := testNotice("/legacy", day(9))
+	if err := store.PutNotices(news, legacy); err != nil {
+		t.Fatal(err)
+	}
+	primed, err := PrimedCategories(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(primed) != 2 {
+		t.Fatalf("PrimedCategories() = %v, want the categories of the cached notices", primed)
+	}
+	if err := MarkPrimed(store, "Events"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The quiet news category loses all its notices.
+	if _, err := Prune(store, RetentionPolicy{MaxNotices: 1}, day(10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Notice(news.ID); err == nil {
+		t.Fatal("news notice was not pruned")
+	}
+	primed, err = PrimedCategories(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, category := range []string{"News", DefaultSource.Category, "Events"} {
+		if _, ok := primed[category]; !ok {
+			t.Errorf("category %s is no longer primed after pruning", category)
+		}
+	}
+}
+
+func Test_BoltStore_Compact(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	var notices []Notice
+	for i := range 200 {
+		n := testNotice(fmt.Sprintf("/n%d", i), time.Date(2025, 1, 1, i, 0, 0, 0, time.UTC))
+		n.Body = strings.Repeat("lorem ipsum dolor sit amet ", 200)
+		notices = append(notices, n)
+	}
+	if err := store.PutNotices(notices...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Prune(store, RetentionPolicy{MaxNotices: 10}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size() >= before.Size() {
+		t.Errorf("size after compacting = %d, before = %d", after.Size(), before.Size())
+	}
+	if count, _ := store.CountNotices(); count != 10 {
+		t.Errorf("CountNotices() = %d after compacting", count)
+	}
+	if seen, _ := LoadSeen(store); len(seen.PrunedBefore) == 0 {
+		t.Errorf("compacting lost the prune date")
+	}
+}
@@ -0,0 +1,130 @@
+package notice
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+// RetryPolicy controls how failed HTTP requests are retried. Transport
+// errors, 5xx responses and 429 Too Many Requests are retried with
+// exponential backoff; a Retry-After header takes precedence over the
+// computed delay.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int `json:"max_attempts"`
+	// BaseDelay is the wait after the first failed attempt; it doubles
+	// after each further failure.
+	BaseDelay common.Duration `json:"base_delay"`
+	// MaxDelay caps the computed backoff delay.
+	MaxDelay common.Duration `json:"max_delay"`
+	// Budget bounds the total time spent on one request including waits.
+	Budget common.Duration `json:"budget"`
+	// Jitter randomly shortens each delay by up to this fraction (0 to 1)
+	// so that clients do not retry in lockstep.
+	Jitter float64 `json:"jitter"`
+}
+
+// DefaultRetryPolicy is used unless the configuration overrides it.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   common.Duration(2 * time.Second),
+	MaxDelay:    common.Duration(30 * time.Second),
+	Budget:      common.Duration(90 * time.Second),
+	Jitter:      0.2,
+}
+
+// WithDefaults returns a copy of p with unset fields taken from
+// DefaultRetryPolicy.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	d := DefaultRetryPolicy
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = d.BaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = d.MaxDelay
+	}
+	if p.Budget == 0 {
+		p.Budget = d.Budget
+	}
+	return p
+}
+
+// Validate reports whether the policy is usable.
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry: max_attempts must be at least 1")
+	}
+	if p.BaseDelay < 0 || p.MaxDelay < 0 || p.Budget < 0 {
+		return fmt.Errorf("retry: delays must not be negative")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry: jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+var (
+	retryMu     sync.RWMutex
+	retryPolicy = DefaultRetryPolicy
+)
+
+// SetRetryPolicy sets the policy used by every request of the package.
+func SetRetryPolicy(p RetryPolicy) {
+	retryMu.Lock()
+	defer retryMu.Unlock()
+	retryPolicy = p
+}
+
+func currentRetryPolicy() RetryPolicy {
+	retryMu.RLock()
+	defer retryMu.RUnlock()
+	return retryPolicy
+}
+
+// jitterFloat returns a random number in [0, 1); replaced in tests.
+var jitterFloat = rand.Float64
+
+// retryable reports whether a response status is worth retrying.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests ||
+		(status >= 500 && status != http.StatusNotImplemented)
+}
+
+// backoff returns the delay before the given retry, numbered from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay.D()
+	for i := 1; i < retry && delay < p.MaxDelay.D(); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay.D() {
+		delay = p.MaxDelay.D()
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(float64(delay) * p.Jitter * jitterFloat())
+	}
+	return delay
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date. It returns false if the header is absent or invalid.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,154 @@
+package notice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+func Test_httpGetWithRetry_policy(t *testing.T) {
+	origJitter := jitterFloat
+	jitterFloat = func() float64 { return 0 }
+	defer func() { jitterFloat = origJitter }()
+
+	policy := RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   common.Duration(time.Second),
+		MaxDelay:    common.Duration(3 * time.Second),
+		Budget:      common.Duration(time.Minute),
+	}
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		policy     RetryPolicy
+		wantStatus int
+		wantCalls  int32
+		wantWaits  []time.Duration
+	}{
+		{
+			name:       "5xx is retried with exponential backoff",
+			statuses:   []int{503, 502, 200},
+			policy:     policy,
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:       "backoff is capped and last response returned",
+			statuses:   []int{500, 500, 500, 500},
+			policy:     policy,
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  4,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name:       "429 honours Retry-After",
+			statuses:   []int{429, 200},
+			retryAfter: "7",
+			policy:     policy,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantWaits:  []time.Duration{7 * time.Second},
+		},
+		{
+			name:       "Retry-After beyond budget gives up",
+			statuses:   []int{429, 200},
+			retryAfter: "120",
+			policy:     policy,
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  1,
+		},
+		{
+			name:       "4xx is not retried",
+			statuses:   []int{404},
+			policy:     policy,
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var waits []time.Duration
+			origSleep := sleep
+			sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			defer func() { sleep = origSleep }()
+
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				status := tt.statuses[min(int(n), len(tt.statuses))-1]
+				if tt.retryAfter != "" && status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			resp, err := httpGetWithRetry(context.Background(), server.URL, tt.policy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(waits, tt.wantWaits) {
+				t.Errorf("waits = %v, want %v", waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{header: "", ok: false},
+		{header: "30", want: 30 * time.Second, ok: true},
+		{header: "Wed, 01 Jan 2025 00:01:00 GMT", want: time.Minute, ok: true},
+		{header: "Tue, 31 Dec 2024 00:00:00 GMT", want: 0, ok: true},
+		{header: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func Test_backoff_jitter(t *testing.T) {
+	origJitter := jitterFloat
+	jitterFloat = func() float64 { return 0.5 }
+	defer func() { jitterFloat = origJitter }()
+
+	p := RetryPolicy{
+		BaseDelay: common.Duration(4 * time.Second),
+		MaxDelay:  common.Duration(time.Minute),
+		Jitter:    0.5,
+	}
+	if got, want := p.backoff(2), 6*time.Second; got != want {
+		t.Errorf("backoff(2) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package notice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// ContentHash returns a hash of the notice's title, description and body.
+// Differences in white space do not change the hash.
+func (n Notice) ContentHash() string {
+	h := sha256.New()
+	for _, field := range []string{n.Title, n.Desc, n.Body} {
+		_, _ = h.Write([]byte(collapseSpace(field)))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Revision is a recorded version of a notice's content.
+type Revision struct {
+	Hash       string    `json:"hash"`
+	Title      string    `json:"title"`
+	Desc       string    `json:"desc"`
+	Body       string    `json:"body,omitempty"`
+	Date       time.Time `json:"date"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+func newRevision(n Notice, at time.Time) Revision {
+	return Revision{
+		Hash:       n.ContentHash(),
+		Title:      n.Title,
+		Desc:       n.Desc,
+		Body:       n.Body,
+		Date:       n.Date,
+		RecordedAt: at,
+	}
+}
+
+// LoadRevisions returns the recorded revisions of the notice with the given
+// ID, oldest first. Notices that never changed have no revisions.
+func LoadRevisions(id string) ([]Revision, error) {
+	store, err := DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Revisions(id)
+}
+
+// RevisedNotices returns the IDs of all notices with recorded revisions.
+func RevisedNotices() ([]string, error) {
+	store, err := DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.RevisedNotices()
+}
+
+// recordRevision stores the content of updated as a new revision of the
+// notice, preceded by previous if this is the first change recorded.
+func recordRevision(store Store, previous, updated Notice) error {
+	revisions, err := store.Revisions(updated.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(revisions) == 0 {
+		revisions = append(revisions, newRevision(previous, now))
+	}
+	revision := newRevision(updated, now)
+	if revisions[len(revisions)-1].Hash == revision.Hash {
+		return nil
+	}
+	revisions = append(revisions, revision)
+	return store.SetRevisions(updated.ID, revisions)
+}
+
+// Text returns the revision as lines of plain text for diffing.
+func (r Revision) Text() []string {
+	lines := []string{"Title: " + r.Title, "Description: " + r.Desc}
+	if r.Body != "" {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(r.Body, "\n")...)
+	}
+	return lines
+}
+
+// DiffLines returns a line diff turning a into b. Unchanged lines start with
+// two spaces, removed lines with "- " and added lines with "+ ".
+func DiffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "- "+a[i])
+			i++
+		default:
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, "+ "+b[j])
+	}
+	return diff
+}
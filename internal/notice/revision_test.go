@@ -0,0 +1,188 @@
+package notice
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_DiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []string
+	}{
+		{
+			name: "identical",
+			a:    []string{"x", "y"},
+			b:    []string{"x", "y"},
+			want: []string{"  x", "  y"},
+		},
+		{
+			name: "changed line",
+			a:    []string{"Title: Exam", "Date: 5 May", "Room 301"},
+			b:    []string{"Title: Exam", "Date: 7 May", "Room 301"},
+			want: []string{"  Title: Exam", "- Date: 5 May", "+ Date: 7 May", "  Room 301"},
+		},
+		{
+			name: "added and removed at the ends",
+			a:    []string{"old", "keep"},
+			b:    []string{"keep", "new"},
+			want: []string{"- old", "  keep", "+ new"},
+		},
+		{
+			name: "from empty",
+			a:    nil,
+			b:    []string{"a"},
+			want: []string{"+ a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mergeCachedNotices_revisions(t *testing.T) {
+	withTempDataDir(t)
+
+	link := "https://www.aiub.edu/class-routine"
+	original := Notice{ID: NoticeID(link), Link: link, Date: time.Now(), Title: "Class Routine", Desc: "Routine for summer"}
+	if _, err := mergeCachedNotices([]Notice{original}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fetching the details of a cached notice is not an edit.
+	detailed := original
+	detailed.Body = "Room 301"
+	updated, err := mergeCachedNotices([]Notice{detailed}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 0 {
+		t.Fatalf("detail fetch reported as update: %+v", updated)
+	}
+
+	// A listing without details keeps the cached body; whitespace is ignored.
+	relisted := original
+	relisted.Desc = "Routine  for summer "
+	if updated, _ = mergeCachedNotices([]Notice{relisted}, nil); len(updated) != 0 {
+		t.Fatalf("unchanged notice reported as update: %+v", updated)
+	}
+
+	edited := detailed
+	edited.Body = "Room 405"
+	if updated, err = mergeCachedNotices([]Notice{edited}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(updated) != 1 || updated[0].Body != "Room 405" {
+		t.Fatalf("edit not reported: %+v", updated)
+	}
+
+	revisions, err := LoadRevisions(original.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[0].Body != "Room 301" || revisions[1].Body != "Room 405" {
+		t.Fatalf("unexpected revisions: %+v", revisions)
+	}
+	if ids, _ := RevisedNotices(); !reflect.DeepEqual(ids, []string{original.ID}) {
+		t.Errorf("RevisedNotices() = %v", ids)
+	}
+
+	cached, _ := GetCachedNotices()
+	if len(cached) != 1 || cached[0].Hash != edited.ContentHash() {
+		t.Errorf("cached hash not updated: %+v", cached)
+	}
+}
+
+func Test_htmlSource_Fetch_bodyEdit(t *testing.T) {
+	withTempDataDir(t)
+	server := newListingServer(t, [][]string{{"/routine"}}, false)
+	server.setDetail("/routine", "Room 301")
+	src := htmlSource{cfg: server.source()}
+	id := server.id("/routine")
+
+	// check fetches the listing after the given notices were published and
+	// returns the status of the listing and the cached notices reported as
+	// edited.
+	check := func(published ...string) (FetchStatus, []Notice) {
+		t.Helper()
+		server.setPages([][]string{append(published, "/routine")})
+		store := mustDefaultStore(t)
+		seen, err := LoadSeen(store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := src.Fetch(context.Background(), seen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		updated, err := CacheResult(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range result.Notices {
+			if err := store.MarkSeen(n.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return result.Status, updated
+	}
+
+	check()
+	if n, _ := mustDefaultStore(t).Notice(id); n.Body != "Room 301" {
+		t.Fatalf("body = %q after the first check", n.Body)
+	}
+
+	// Only the body changes; the listing is answered with 304.
+	server.setDetail("/routine", "Room 405")
+	status, updated := check()
+	if status != FetchNotModified {
+		t.Fatalf("listing status = %q, want %q", status, FetchNotModified)
+	}
+	if len(updated) != 1 || updated[0].ID != id || updated[0].Body != "Room 405" {
+		t.Fatalf("body edit not reported: %+v", updated)
+	}
+	if revisions, _ := LoadRevisions(id); len(revisions) != 2 {
+		t.Errorf("revisions = %+v", revisions)
+	}
+
+	// The body changes as another notice is published.
+	server.setDetail("/routine", "Room 506")
+	if _, updated := check("/fees"); len(updated) != 1 || updated[0].Body != "Room 506" {
+		t.Fatalf("body edit not reported with a changed listing: %+v", updated)
+	}
+
+	// An unchanged page is answered with 304 and not downloaded again.
+	requests := server.detailRequests
+	if _, updated := check("/exam", "/fees"); len(updated) != 0 {
+		t.Errorf("unchanged notice reported as edited: %+v", updated)
+	}
+	if got := server.detailRequests - requests; got != 1 {
+		t.Errorf("%d detail pages downloaded, want only the new notice's", got)
+	}
+	if _, updated := check("/exam", "/fees"); len(updated) != 0 {
+		t.Errorf("unchanged notice reported as edited with an unchanged listing: %+v", updated)
+	}
+
+	// Notices first seen long ago are no longer checked.
+	store := mustDefaultStore(t)
+	n, _ := store.Notice(id)
+	n.FirstSeen = time.Now().Add(-2 * detailRecheckAge)
+	if err := store.PutNotices(n); err != nil {
+		t.Fatal(err)
+	}
+	server.setDetail("/routine", "Room 607")
+	if _, updated := check("/exam", "/fees"); len(updated) != 0 {
+		t.Errorf("old notice checked again: %+v", updated)
+	}
+	if _, updated := check("/holiday", "/exam", "/fees"); len(updated) != 0 {
+		t.Errorf("old notice checked again with a changed listing: %+v", updated)
+	}
+}
@@ -0,0 +1,143 @@
+package notice
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+// ErrNewerSchema is returned when writing to a store created by a newer
+// version of the application, whose layout this version does not know.
+var ErrNewerSchema = errors.New("store was written by a newer version")
+
+// metaSchema is the metadata key of the schema envelope.
+const metaSchema = "schema"
+
+// Schema describes the layout of a store and who last wrote it.
+type Schema struct {
+	Version   int       `json:"schema_version"`
+	Writer    string    `json:"writer_version"`
+	WrittenAt time.Time `json:"written_at"`
+}
+
+// migration upgrades a store to version from the version before it.
+type migration struct {
+	version     int
+	description string
+	apply       func(tx *bolt.Tx) error
+}
+
+// migrations upgrade a store step by step to schemaVersion. They are applied
+// in order, in the transaction of the first write after an upgrade, so a
+// failed migration leaves the store untouched. Stores written before the
+// schema was recorded are at version 0; the JSON files of even older
+// versions are imported by importJSONState.
+var migrations = []migration{
+	{version: 1, description: "create buckets", apply: createBuckets(
+		bucketNotices, bucketByDate, bucketSeen, bucketRead, bucketRevisions, bucketMeta,
+	)},
+	{version: 2, description: "add annotations", apply: createBuckets(bucketAnnotations)},
+	{version: 3, description: "build search index", apply: indexAll},
+	{version: 4, description: "keep prune dates per category", apply: splitPruneDates},
+	{version: 5, description: "record notices marked unread", apply: createBuckets(bucketUnread)},
+}
+
+// schemaVersion is the schema version this version of the application
+// writes.
+var schemaVersion = migrations[len(migrations)-1].version
+
+func createBuckets(names ...[]byte) func(tx *bolt.Tx) error {
+	return func(tx *bolt.Tx) error {
+		for _, name := range names {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("create bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// splitPruneDates rewrites the single prune date of older stores as the
+// prune date of each category primed before it; see decodePruneDates.
+func splitPruneDates(tx *bolt.Tx) error {
+	if tx.Bucket(bucketMeta).Get([]byte(metaPruned)) == nil {
+		return nil
+	}
+	dates, err := pruneDates(tx)
+	if err != nil {
+		return err
+	}
+	return putPruneDates(tx, dates)
+}
+
+func readSchema(tx *bolt.Tx) (Schema, error) {
+	var schema Schema
+	b := tx.Bucket(bucketMeta)
+	if b == nil {
+		return schema, nil
+	}
+	data := b.Get([]byte(metaSchema))
+	if data == nil {
+		return schema, nil
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return schema, fmt.Errorf("decode schema: %w", err)
+	}
+	return schema, nil
+}
+
+// migrate brings the store up to schemaVersion, refusing stores with a newer
+// schema, and records this version as the last writer.
+func migrate(tx *bolt.Tx) error {
+	schema, err := readSchema(tx)
+	if err != nil {
+		return err
+	}
+	if schema.Version > schemaVersion {
+		return fmt.Errorf("%w: %s has schema version %d, written by aiub-notice %s, "+
+			"but this version only supports up to %d; upgrade aiub-notice to modify it",
+			ErrNewerSchema, tx.DB().Path(), schema.Version, schema.Writer, schemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= schema.Version {
+			continue
+		}
+		if err := m.apply(tx); err != nil {
+			return fmt.Errorf("migrate store to schema version %d (%s): %w", m.version, m.description, err)
+		}
+		logger.L().Info("migrated store",
+			slog.Int("schema_version", m.version),
+			slog.String("migration", m.description),
+		)
+	}
+
+	data, err := json.Marshal(Schema{
+		Version:   schemaVersion,
+		Writer:    common.Version,
+		WrittenAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("encode schema: %w", err)
+	}
+	return tx.Bucket(bucketMeta).Put([]byte(metaSchema), data)
+}
+
+// Schema returns the schema envelope of the store. A store that was never
+// written has the zero schema.
+func (s *BoltStore) Schema() (Schema, error) {
+	var schema Schema
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		schema, err = readSchema(tx)
+		return err
+	})
+	return schema, err
+}
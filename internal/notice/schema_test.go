@@ -0,0 +1,100 @@
+package notice
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+func Test_migrate(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	n := Notice{ID: NoticeID("https://www.aiub.edu/n"), Link: "https://www.aiub.edu/n"}
+
+	if err := store.PutNotices(n); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := store.Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Version != schemaVersion || schema.Writer != common.Version || schema.WrittenAt.IsZero() {
+		t.Errorf("schema after first write = %+v", schema)
+	}
+
+	// Pending migrations run in order, once.
+	var applied []int
+	step := func(version int) migration {
+		return migration{version: version, description: "test", apply: func(*bolt.Tx) error {
+			applied = append(applied, version)
+			return nil
+		}}
+	}
+	original, originalVersion := migrations, schemaVersion
+	t.Cleanup(func() { migrations, schemaVersion = original, originalVersion })
+	migrations = append(append([]migration{}, original...), step(originalVersion+1), step(originalVersion+2))
+	schemaVersion = originalVersion + 2
+
+	for range 2 {
+		if err := store.MarkSeen(n.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []int{originalVersion + 1, originalVersion + 2}; len(applied) != 2 || applied[0] != want[0] || applied[1] != want[1] {
+		t.Errorf("applied migrations = %v, want %v", applied, want)
+	}
+
+	// A store from a newer version can be read but not written.
+	migrations, schemaVersion = original, originalVersion
+	err = store.MarkSeen("0123456789abcdef")
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("write to newer store: err = %v", err)
+	}
+	if _, err := store.Notice(n.ID); err != nil {
+		t.Errorf("read from newer store: %v", err)
+	}
+	if seen, _ := store.Seen(); len(seen) != 1 {
+		t.Errorf("refused write changed the store: seen = %v", seen)
+	}
+}
+
+func Test_readSchema_legacy(t *testing.T) {
+	// Stores written before the schema was recorded are at version 0.
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	legacy := Notice{ID: NoticeID("https://www.aiub.edu/legacy"), Link: "https://www.aiub.edu/legacy", Title: "Holiday Notice"}
+	err := func() error {
+		db, err := store.open()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = db.Close() }()
+		return db.Update(func(tx *bolt.Tx) error {
+			if err := migrations[0].apply(tx); err != nil {
+				return err
+			}
+			data, _ := json.Marshal(legacy)
+			return tx.Bucket(bucketNotices).Put([]byte(legacy.ID), data)
+		})
+	}()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schema, err := store.Schema(); err != nil || schema.Version != 0 {
+		t.Fatalf("schema = %+v, err = %v", schema, err)
+	}
+	if err := store.SetMeta("key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	var schema Schema
+	if _, err := store.Meta(metaSchema, &schema); err != nil || schema.Version != schemaVersion {
+		t.Errorf("schema after upgrade = %+v, err = %v", schema, err)
+	}
+	if results, _ := store.Search(ParseQuery("holiday")); len(results) != 1 {
+		t.Errorf("notices stored before the upgrade not indexed: %+v", results)
+	}
+}
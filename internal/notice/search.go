@@ -0,0 +1,341 @@
+package notice
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed search query. A notice matches if it contains every
+// term and every phrase, and passes the filters.
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+
+	// Since and Until limit the notice dates to a range of calendar days,
+	// both inclusive; zero means no limit. Only the day of each is used, so
+	// the time zone they are given in does not matter.
+	Since    time.Time
+	Until    time.Time
+	Category string
+}
+
+// ParseQuery parses a search query. Words in double quotes form a phrase
+// that must appear in that order.
+func ParseQuery(s string) Query {
+	var q Query
+	for i, part := range strings.Split(s, `"`) {
+		words := tokenTerms(tokenize(part))
+		if i%2 == 1 && len(words) > 1 {
+			q.Phrases = append(q.Phrases, words)
+			continue
+		}
+		q.Terms = append(q.Terms, words...)
+	}
+	return q
+}
+
+// IsEmpty reports whether the query has nothing to search for.
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// words returns the distinct words of the terms and phrases.
+func (q Query) words() []string {
+	seen := make(map[string]struct{})
+	var words []string
+	add := func(w string) {
+		if _, ok := seen[w]; !ok {
+			seen[w] = struct{}{}
+			words = append(words, w)
+		}
+	}
+	for _, t := range q.Terms {
+		add(t)
+	}
+	for _, p := range q.Phrases {
+		for _, w := range p {
+			add(w)
+		}
+	}
+	return words
+}
+
+// accepts reports whether n passes the filters of the query. A notice is
+// dated by the day shown on the site, in the time zone of its source.
+func (q Query) accepts(n Notice) bool {
+	day := calendarDay(n.Date)
+	if !q.Since.IsZero() && day.Before(calendarDay(q.Since)) {
+		return false
+	}
+	if !q.Until.IsZero() && day.After(calendarDay(q.Until)) {
+		return false
+	}
+	if q.Category != "" && !strings.EqualFold(n.Category, q.Category) {
+		return false
+	}
+	return true
+}
+
+// calendarDay returns the calendar day of t in its own location, as a
+// midnight in UTC that can be compared with other days.
+func calendarDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Matches reports whether n contains every term and phrase of q and passes
+// its filters. It checks a single notice without the index; an empty query
+// matches every notice that passes the filters.
+func (q Query) Matches(n Notice) bool {
+	if !q.accepts(n) {
+		return false
+	}
+	terms := postings(n)
+	found := make(map[string]posting)
+	for _, w := range q.words() {
+		p, ok := terms[w]
+		if !ok {
+			return false
+		}
+		found[w] = *p
+	}
+	for _, phrase := range q.Phrases {
+		if !containsPhrase(phrase, found) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchResult is a notice matching a query.
+type SearchResult struct {
+	Notice Notice
+	Score  float64
+	// Snippet is an excerpt of the notice around the first match, with
+	// Highlights holding the byte ranges of the matched words in it.
+	Snippet    string
+	Highlights [][2]int
+}
+
+// Highlight returns the snippet with every matched word passed through
+// mark.
+func (r SearchResult) Highlight(mark func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, h := range r.Highlights {
+		b.WriteString(r.Snippet[last:h[0]])
+		b.WriteString(mark(r.Snippet[h[0]:h[1]]))
+		last = h[1]
+	}
+	b.WriteString(r.Snippet[last:])
+	return b.String()
+}
+
+// HighlightWords returns s with every word of q in it passed through mark.
+func HighlightWords(s string, q Query, mark func(string) string) string {
+	return SearchResult{Snippet: s, Highlights: matchRanges(s, q)}.Highlight(mark)
+}
+
+// matchRanges returns the byte ranges of the words of q in s.
+func matchRanges(s string, q Query) [][2]int {
+	words := make(map[string]struct{})
+	for _, w := range q.words() {
+		words[w] = struct{}{}
+	}
+	var ranges [][2]int
+	for _, t := range tokenize(s) {
+		if _, ok := words[t.term]; ok {
+			ranges = append(ranges, [2]int{t.start, t.end})
+		}
+	}
+	return ranges
+}
+
+// token is a word of a text with its byte offsets.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits s into lowercase words of letters and digits.
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range s {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			tokens = append(tokens, token{strings.ToLower(s[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(s[start:]), start, len(s)})
+	}
+	return tokens
+}
+
+func tokenTerms(tokens []token) []string {
+	terms := make([]string, len(tokens))
+	for i, t := range tokens {
+		terms[i] = t.term
+	}
+	return terms
+}
+
+// Indexed fields of a notice, with the weight of a match in each.
+const (
+	fieldTitle = iota
+	fieldDesc
+	fieldBody
+	fieldCount
+)
+
+// A match in the title outweighs matches in both other fields, so a notice
+// about a topic ranks above one that mentions it.
+var fieldWeights = [fieldCount]float64{fieldTitle: 5, fieldDesc: 2, fieldBody: 1}
+
+func indexedFields(n Notice) [fieldCount]string {
+	return [fieldCount]string{fieldTitle: n.Title, fieldDesc: n.Desc, fieldBody: n.Body}
+}
+
+// posting holds the positions of a term in each field of a notice.
+type posting [fieldCount][]int
+
+// postings returns the postings of every term of n.
+func postings(n Notice) map[string]*posting {
+	terms := make(map[string]*posting)
+	for field, text := range indexedFields(n) {
+		for pos, t := range tokenize(text) {
+			p, ok := terms[t.term]
+			if !ok {
+				p = new(posting)
+				terms[t.term] = p
+			}
+			p[field] = append(p[field], pos)
+		}
+	}
+	return terms
+}
+
+// score rates a notice containing all words of q, given the postings of
+// each word in it and the inverse document frequency of each word. It
+// returns false if a phrase of q does not occur in the notice.
+func score(q Query, found map[string]posting, idf map[string]float64) (float64, bool) {
+	total := 0.0
+	for w, p := range found {
+		for field, positions := range p {
+			if len(positions) > 0 {
+				// repeated words add less and less, up to twice a single one
+				tf := float64(len(positions))
+				total += idf[w] * fieldWeights[field] * 2 * tf / (tf + 1)
+			}
+		}
+	}
+
+	for _, phrase := range q.Phrases {
+		matches := 0.0
+		for field := range fieldCount {
+			for _, start := range found[phrase[0]][field] {
+				if phraseAt(phrase, found, field, start) {
+					weight := 0.0
+					for _, w := range phrase {
+						weight += idf[w]
+					}
+					matches += fieldWeights[field] * weight
+				}
+			}
+		}
+		if matches == 0 {
+			return 0, false
+		}
+		total += matches
+	}
+	return total, true
+}
+
+func containsPhrase(phrase []string, found map[string]posting) bool {
+	for field := range fieldCount {
+		for _, start := range found[phrase[0]][field] {
+			if phraseAt(phrase, found, field, start) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func phraseAt(phrase []string, found map[string]posting, field, start int) bool {
+	for i, w := range phrase[1:] {
+		positions := found[w][field]
+		j := sort.SearchInts(positions, start+i+1)
+		if j == len(positions) || positions[j] != start+i+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// snippetWords is the length of a snippet in words.
+const snippetWords = 24
+
+// snippet returns an excerpt of the body of n, or of its description or
+// title, around the first word of q, with the ranges of the matched words.
+func snippet(n Notice, q Query) (string, [][2]int) {
+	words := make(map[string]struct{})
+	for _, w := range q.words() {
+		words[w] = struct{}{}
+	}
+
+	var text string
+	var tokens []token
+	first := -1
+	for _, candidate := range []string{n.Body, n.Desc, n.Title} {
+		tokens = tokenize(candidate)
+		for i, t := range tokens {
+			if _, ok := words[t.term]; ok {
+				first = i
+				break
+			}
+		}
+		if first >= 0 {
+			text = candidate
+			break
+		}
+	}
+	if first < 0 {
+		return "", nil
+	}
+
+	from := max(first-snippetWords/3, 0)
+	to := min(from+snippetWords, len(tokens))
+	excerpt := collapseSpace(text[tokens[from].start:tokens[to-1].end])
+	prefix, suffix := "", ""
+	if from > 0 {
+		prefix = "… "
+	}
+	if to < len(tokens) {
+		suffix = " …"
+	}
+
+	highlights := matchRanges(excerpt, q)
+	for i := range highlights {
+		highlights[i][0] += len(prefix)
+		highlights[i][1] += len(prefix)
+	}
+	return prefix + excerpt + suffix, highlights
+}
+
+// rankResults orders results by score, then by date, newest first.
+func rankResults(results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Notice.Date.After(results[j].Notice.Date)
+	})
+}
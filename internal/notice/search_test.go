@@ -0,0 +1,220 @@
+package notice
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_ParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		terms   []string
+		phrases [][]string
+	}{
+		{query: "Midterm Exam", terms: []string{"midterm", "exam"}},
+		{query: `"make up" exam`, terms: []string{"exam"}, phrases: [][]string{{"make", "up"}}},
+		{query: `"routine" fall-2025`, terms: []string{"routine", "fall", "2025"}},
+		{query: `"unterminated phrase`, phrases: [][]string{{"unterminated", "phrase"}}},
+		{query: " ,. "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q := ParseQuery(tt.query)
+			if !reflect.DeepEqual(q.Terms, tt.terms) || !reflect.DeepEqual(q.Phrases, tt.phrases) {
+				t.Errorf("ParseQuery() = %q, %q; want %q, %q", q.Terms, q.Phrases, tt.terms, tt.phrases)
+			}
+		})
+	}
+}
+
+func Test_Query_Matches(t *testing.T) {
+	n := Notice{
+		Title:    "Make Up Exam Routine",
+		Body:     "Students must pay the fees before the make up exam week.",
+		Date:     time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC),
+		Category: "Notices",
+	}
+	// the day of the notice in Dhaka starts on the previous day in New York
+	newYork := time.FixedZone("EST", -5*60*60)
+	dhaka := n
+	dhaka.Date = time.Date(2025, 3, 5, 0, 0, 0, 0, time.FixedZone("+06", 6*60*60))
+
+	tests := []struct {
+		query        string
+		category     string
+		since, until time.Time
+		notice       *Notice
+		want         bool
+	}{
+		{query: "", want: true},
+		{query: "routine FEES", want: true},
+		{query: "routine convocation", want: false},
+		{query: `"make up" exam`, want: true},
+		{query: `"up make"`, want: false},
+		{query: "", category: "news", want: false},
+		{since: time.Date(2025, 3, 5, 0, 0, 0, 0, newYork), notice: &dhaka, want: true},
+		{until: time.Date(2025, 3, 5, 0, 0, 0, 0, newYork), notice: &dhaka, want: true},
+		{until: time.Date(2025, 3, 4, 0, 0, 0, 0, newYork), notice: &dhaka, want: false},
+		{since: time.Date(2025, 3, 6, 0, 0, 0, 0, newYork), notice: &dhaka, want: false},
+	}
+	for _, tt := range tests {
+		name := tt.query + "/" + tt.category
+		if tt.notice != nil {
+			name += "/" + tt.since.Format(time.DateOnly) + ".." + tt.until.Format(time.DateOnly)
+		}
+		t.Run(name, func(t *testing.T) {
+			q := ParseQuery(tt.query)
+			q.Category, q.Since, q.Until = tt.category, tt.since, tt.until
+			n := n
+			if tt.notice != nil {
+				n = *tt.notice
+			}
+			if got := q.Matches(n); got != tt.want {
+				t.Errorf("Matches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_BoltStore_Search(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	notice := func(path, title, desc, body string, date time.Time, category string) Notice {
+		link := "https://www.aiub.edu" + path
+		return Notice{ID: NoticeID(link), Link: link, Title: title, Desc: desc, Body: body, Date: date, Category: category}
+	}
+	routine := notice("/routine", "Make Up Exam Routine", "Routine of make up exams", "", day(3), "Notices")
+	fees := notice("/fees", "Fee Payment Deadline", "Pay fees before the exam",
+		"Students must pay the second installment before the make up exam week. Late payment incurs a fine.", day(5), "Notices")
+	news := notice("/news", "Convocation", "Exam results and convocation", "", day(7), "News")
+	if err := store.PutNotices(routine, fees, news); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := func(results []SearchResult) []string {
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.Notice.Title)
+		}
+		return ids
+	}
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "title matches rank first", query: ParseQuery("exam"), want: []string{routine.Title, fees.Title, news.Title}},
+		{name: "all terms required", query: ParseQuery("exam fine"), want: []string{fees.Title}},
+		{name: "phrase", query: ParseQuery(`"up exam"`), want: []string{routine.Title, fees.Title}},
+		{name: "phrase in order", query: ParseQuery(`"exam up"`)},
+		{name: "unknown term", query: ParseQuery("exam library")},
+		{name: "category", query: Query{Terms: []string{"exam"}, Category: "news"}, want: []string{news.Title}},
+		{name: "date range", query: Query{Terms: []string{"exam"}, Since: day(4), Until: day(5)}, want: []string{fees.Title}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := store.Search(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(results); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	results, _ := store.Search(ParseQuery("installment"))
+	if len(results) != 1 {
+		t.Fatalf("body not indexed: %q", ids(results))
+	}
+	marked := results[0].Highlight(func(s string) string { return "[" + s + "]" })
+	if !strings.HasPrefix(marked, "Students must pay the second [installment] before") {
+		t.Errorf("snippet = %q", marked)
+	}
+
+	// Changed notices are indexed again.
+	fees.Body = ""
+	if err := store.PutNotices(fees); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := store.Search(ParseQuery("installment")); len(results) != 0 {
+		t.Errorf("stale postings found: %q", ids(results))
+	}
+}
+
+func Test_BoltStore_Search_dates(t *testing.T) {
+	// The user is in New York while notices are dated in Dhaka.
+	local := time.Local
+	time.Local = time.FixedZone("EST", -5*60*60)
+	defer func() { time.Local = local }()
+
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	dhaka := time.FixedZone("+06", 6*60*60)
+	link := "https://www.aiub.edu/routine"
+	routine := Notice{ID: NoticeID(link), Link: link, Title: "Exam Routine", Date: time.Date(2025, 3, 5, 0, 0, 0, 0, dhaka)}
+	if err := store.PutNotices(routine); err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		since, until string
+		want         bool
+	}{
+		{since: "2025-03-05", until: "2025-03-05", want: true},
+		{since: "2025-03-05", want: true},
+		{until: "2025-03-05", want: true},
+		{since: "2025-03-06", want: false},
+		{until: "2025-03-04", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.since+".."+tt.until, func(t *testing.T) {
+			q := ParseQuery("routine")
+			if tt.since != "" {
+				q.Since = day(tt.since)
+			}
+			if tt.until != "" {
+				q.Until = day(tt.until)
+			}
+			results, err := store.Search(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(results) == 1; got != tt.want {
+				t.Errorf("Search() found the notice: %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_snippet(t *testing.T) {
+	words := strings.Fields(strings.Repeat("lorem ipsum dolor ", 20))
+	words[30] = "Deadline"
+	n := Notice{Title: "Fees", Body: strings.Join(words, "\n")}
+
+	result := SearchResult{}
+	result.Snippet, result.Highlights = snippet(n, ParseQuery("deadline"))
+	got := result.Highlight(strings.ToUpper)
+	if !strings.HasPrefix(got, "… ") || !strings.HasSuffix(got, " …") || !strings.Contains(got, " DEADLINE ") {
+		t.Errorf("snippet = %q", got)
+	}
+	if len(strings.Fields(got)) != snippetWords+2 {
+		t.Errorf("snippet has %d words, want %d", len(strings.Fields(got))-2, snippetWords)
+	}
+
+	// Without a match in the body, the title is used.
+	n.Body = ""
+	if s, h := snippet(n, ParseQuery("fees")); s != "Fees" || len(h) != 1 {
+		t.Errorf("snippet = %q, %v", s, h)
+	}
+}
@@ -0,0 +1,85 @@
+package notice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Source kinds understood by NewSource.
+const (
+	KindHTML = "html"
+	KindFeed = "feed"
+)
+
+// Source is a feed of notices, such as a scraped listing page or an RSS feed.
+type Source interface {
+	// Config returns the definition the source was created from.
+	Config() SourceConfig
+	// Fetch returns the source's latest notices. Notices in seen are
+	// already known; sources may use this to stop paginating early.
+	Fetch(ctx context.Context, seen SeenSet) (FetchResult, error)
+}
+
+// FetchResult holds the notices returned by a Source along with metadata
+// about the fetch.
+type FetchResult struct {
+	Source    string
+	Status    FetchStatus
+	Notices   []Notice
+	Pages     int
+	FetchedAt time.Time
+	// Withdrawn holds cached notices found to have been taken down.
+	Withdrawn []Notice
+	// Edited holds cached notices whose page was edited while the listing
+	// stayed unchanged. It is only set on unchanged results.
+	Edited []Notice
+
+	// validators are saved by CacheResult once the notices are cached.
+	validators map[string]Validator
+}
+
+// Changed reports whether the fetch returned new content that needs to be
+// processed. Unchanged results carry no listed notices. Sources that do not report
+// a status are always treated as changed.
+func (r FetchResult) Changed() bool {
+	return r.Status == "" || r.Status == FetchChanged
+}
+
+// SourceFactory creates a Source from its definition.
+type SourceFactory func(cfg SourceConfig) (Source, error)
+
+var sourceFactories = make(map[string]SourceFactory)
+
+// RegisterSource makes a source kind available to NewSource. It panics if
+// the kind is registered twice.
+func RegisterSource(kind string, factory SourceFactory) {
+	if _, dup := sourceFactories[kind]; dup {
+		panic("notice: source kind registered twice: " + kind)
+	}
+	sourceFactories[kind] = factory
+}
+
+// SourceKinds returns the registered source kinds.
+func SourceKinds() []string {
+	kinds := make([]string, 0, len(sourceFactories))
+	for kind := range sourceFactories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// NewSource creates the Source described by cfg.
+func NewSource(cfg SourceConfig) (Source, error) {
+	kind := cfg.Kind
+	if kind == "" {
+		kind = KindHTML
+	}
+	factory, ok := sourceFactories[kind]
+	if !ok {
+		return nil, fmt.Errorf("source %q: unknown kind %q", cfg.Name, kind)
+	}
+	return factory(cfg)
+}
@@ -0,0 +1,203 @@
+package notice
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceConfig declaratively describes a notice listing page: where it lives,
+// how it is paginated and which elements hold each notice field. Kind selects
+// the Source implementation; for feed sources only the name, category and
+// URL fields apply.
+type SourceConfig struct {
+	Name        string    `json:"name"`
+	Kind        string    `json:"kind"`
+	Category    string    `json:"category"`
+	Enabled     *bool     `json:"enabled,omitempty"`
+	BaseURL     string    `json:"base_url"`
+	ListPath    string    `json:"list_path"`
+	PageParam   string    `json:"page_param"`
+	Selectors   Selectors `json:"selectors"`
+	DateLayouts []string  `json:"date_layouts"`
+	LinkAttr    string    `json:"link_attr"`
+	Timezone    string    `json:"timezone"`
+}
+
+// Selectors are the CSS selectors used to scrape a source. Item selects each
+// notice card on the listing; Title, Desc, Date and Link are relative to it.
+// The Detail selectors apply to a notice's own page; DetailBody candidates
+// are tried in order.
+type Selectors struct {
+	Item         string   `json:"item"`
+	Title        string   `json:"title"`
+	Desc         string   `json:"desc"`
+	Date         string   `json:"date"`
+	Link         string   `json:"link"`
+	DetailBody   []string `json:"detail_body"`
+	DetailPosted string   `json:"detail_posted"`
+	DetailAuthor string   `json:"detail_author"`
+}
+
+// DefaultSource is the built-in definition of the AIUB notice page. Every
+// other built-in source and any configured source inherit unset fields from it.
+var DefaultSource = SourceConfig{
+	Name:      "notices",
+	Kind:      KindHTML,
+	Category:  "Notices",
+	Enabled:   enabled(true),
+	BaseURL:   "https://www.aiub.edu",
+	ListPath:  "/category/notices",
+	PageParam: "pageNo",
+	Selectors: Selectors{
+		Item:         "div.notification",
+		Title:        "h2.title",
+		Desc:         "p.desc",
+		Date:         "div.date-custom",
+		Link:         "a",
+		DetailBody:   []string{"div.notice-details", "div.question-column", "article"},
+		DetailPosted: "div.date-custom, span.time, time",
+		DetailAuthor: "div.author, span.author, div.department",
+	},
+	DateLayouts: []string{"2 Jan 2006"},
+	LinkAttr:    "href",
+	Timezone:    "Asia/Dhaka",
+}
+
+// BuiltinSources returns the AIUB categories and department pages known out
+// of the box. Only the notice category is enabled by default.
+func BuiltinSources() []SourceConfig {
+	return []SourceConfig{
+		DefaultSource,
+		builtin("news", "News", "/category/news"),
+		builtin("events", "Events", "/category/events"),
+		builtin("admission", "Admission", "/category/admission-notices"),
+		builtin("fst", "FST", "/category/fst-notices"),
+		builtin("fba", "FBA", "/category/fba-notices"),
+		builtin("fass", "FASS", "/category/fass-notices"),
+		builtin("foe", "FoE", "/category/foe-notices"),
+	}
+}
+
+func builtin(name, category, listPath string) SourceConfig {
+	s := DefaultSource
+	s.Name = name
+	s.Category = category
+	s.Enabled = enabled(false)
+	s.ListPath = listPath
+	return s
+}
+
+func enabled(v bool) *bool { return &v }
+
+// IsEnabled reports whether the source should be fetched.
+func (s SourceConfig) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// WithDefaults returns a copy of s with every unset field taken from the
+// built-in source of the same name, or from DefaultSource for new names, so
+// a config entry only needs the values it changes.
+func (s SourceConfig) WithDefaults() SourceConfig {
+	d := DefaultSource
+	if s.Name != "" {
+		// New sources are categorized under their own name.
+		d.Category = s.Name
+	}
+	for _, b := range BuiltinSources() {
+		if s.Name != "" && b.Name == s.Name {
+			d = b
+			break
+		}
+	}
+	fill := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+
+	if s.Enabled == nil {
+		s.Enabled = enabled(*d.Enabled)
+	}
+	fill(&s.Name, d.Name)
+	fill(&s.Kind, d.Kind)
+	fill(&s.Category, d.Category)
+	fill(&s.BaseURL, d.BaseURL)
+	fill(&s.ListPath, d.ListPath)
+	fill(&s.PageParam, d.PageParam)
+	fill(&s.LinkAttr, d.LinkAttr)
+	fill(&s.Timezone, d.Timezone)
+	fill(&s.Selectors.Item, d.Selectors.Item)
+	fill(&s.Selectors.Title, d.Selectors.Title)
+	fill(&s.Selectors.Desc, d.Selectors.Desc)
+	fill(&s.Selectors.Date, d.Selectors.Date)
+	fill(&s.Selectors.Link, d.Selectors.Link)
+	fill(&s.Selectors.DetailPosted, d.Selectors.DetailPosted)
+	fill(&s.Selectors.DetailAuthor, d.Selectors.DetailAuthor)
+	if len(s.Selectors.DetailBody) == 0 {
+		s.Selectors.DetailBody = d.Selectors.DetailBody
+	}
+	if len(s.DateLayouts) == 0 {
+		s.DateLayouts = d.DateLayouts
+	}
+
+	return s
+}
+
+// Validate reports whether the source definition is usable.
+func (s SourceConfig) Validate() error {
+	u, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return fmt.Errorf("source %q: invalid base_url: %w", s.Name, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("source %q: base_url must be an http(s) URL", s.Name)
+	}
+	if _, ok := sourceFactories[s.Kind]; !ok {
+		return fmt.Errorf("source %q: unknown kind %q", s.Name, s.Kind)
+	}
+	if s.Kind == KindHTML && s.Selectors.Item == "" {
+		return fmt.Errorf("source %q: selectors.item is required", s.Name)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("source %q: invalid timezone: %w", s.Name, err)
+	}
+	return nil
+}
+
+// URL returns the address of the first listing page or feed.
+func (s SourceConfig) URL() string {
+	return s.pageURL(1)
+}
+
+// pageURL returns the URL of the given listing page, numbered from 1.
+func (s SourceConfig) pageURL(page int) string {
+	u := strings.TrimSuffix(s.BaseURL, "/") + s.ListPath
+	if page > 1 {
+		sep := "?"
+		if strings.Contains(u, "?") {
+			sep = "&"
+		}
+		u += sep + s.PageParam + "=" + strconv.Itoa(page)
+	}
+	return u
+}
+
+// parseDate parses a listing or detail date using the source's layouts.
+func (s SourceConfig) parseDate(value string, layouts []string) (time.Time, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("loading location: %w", err)
+	}
+
+	err = fmt.Errorf("no date layouts configured")
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
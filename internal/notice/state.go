@@ -0,0 +1,563 @@
+package notice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// stateFormat is the version of the State format written by this version of
+// the application.
+const stateFormat = 1
+
+// State is everything a store knows about notices, in a form that can be
+// moved to another machine and merged into its store.
+type State struct {
+	Format     int       `json:"format"`
+	Machine    string    `json:"machine,omitempty"`
+	ExportedAt time.Time `json:"exported_at,omitzero"`
+
+	Notices      []Notice              `json:"notices"`
+	Seen         []string              `json:"seen"`
+	PrunedBefore map[string]time.Time  `json:"pruned_before,omitempty"`
+	Read         map[string]time.Time  `json:"read,omitempty"`
+	Unread       map[string]time.Time  `json:"unread,omitempty"`
+	Revisions    map[string][]Revision `json:"revisions,omitempty"`
+	Annotations  map[string]Annotation `json:"annotations,omitempty"`
+}
+
+// ReadState decodes a state written by WriteState.
+func ReadState(r io.Reader) (State, error) {
+	var decoded struct {
+		State
+		// PrunedBefore is decoded apart, as older versions wrote a single
+		// date.
+		PrunedBefore json.RawMessage `json:"pruned_before"`
+	}
+	if err := json.NewDecoder(r).Decode(&decoded); err != nil {
+		return State{}, fmt.Errorf("decode state: %w", err)
+	}
+	s := decoded.State
+	if s.Format > stateFormat {
+		return State{}, fmt.Errorf("state has format %d, this version reads up to %d; upgrade aiub-notice", s.Format, stateFormat)
+	}
+	if len(decoded.PrunedBefore) > 0 {
+		var err error
+		s.PrunedBefore, err = decodePruneDates(decoded.PrunedBefore, func() ([]string, error) {
+			return noticeCategories(s.Notices), nil
+		})
+		if err != nil {
+			return State{}, fmt.Errorf("decode state: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// WriteState encodes s as JSON.
+func WriteState(w io.Writer, s State) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+// ImportStats counts what importing a state changed.
+type ImportStats struct {
+	Notices     int
+	Seen        int
+	Read        int
+	Revisions   int
+	Annotations int
+}
+
+// IsZero reports whether the import changed nothing.
+func (s ImportStats) IsZero() bool {
+	return s == ImportStats{}
+}
+
+func (s *ImportStats) add(o ImportStats) {
+	s.Notices += o.Notices
+	s.Seen += o.Seen
+	s.Read += o.Read
+	s.Revisions += o.Revisions
+	s.Annotations += o.Annotations
+}
+
+func (s *BoltStore) ExportState() (State, error) {
+	state := State{
+		Format:      stateFormat,
+		Seen:        []string{},
+		Read:        make(map[string]time.Time),
+		Unread:      make(map[string]time.Time),
+		Revisions:   make(map[string][]Revision),
+		Annotations: make(map[string]Annotation),
+	}
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		if state.Notices, err = noticesSince(tx, time.Time{}, nil); err != nil {
+			return err
+		}
+		if state.PrunedBefore, err = pruneDates(tx); err != nil {
+			return err
+		}
+
+		if err := forEach(tx, bucketSeen, func(k, _ []byte) error {
+			state.Seen = append(state.Seen, string(k))
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := forEach(tx, bucketRead, func(k, v []byte) error {
+			var at time.Time
+			if err := at.UnmarshalBinary(v); err != nil {
+				return fmt.Errorf("decode read time of %s: %w", k, err)
+			}
+			state.Read[string(k)] = at
+			return nil
+		}); err != nil {
+			return err
+		}
+		// notices marked unread are exported too, so the change wins over
+		// older read times elsewhere
+		if err := forEach(tx, bucketUnread, func(k, v []byte) error {
+			var at time.Time
+			if err := at.UnmarshalBinary(v); err != nil {
+				return fmt.Errorf("decode unread time of %s: %w", k, err)
+			}
+			state.Unread[string(k)] = at
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := forEach(tx, bucketRevisions, func(k, v []byte) error {
+			var revisions []Revision
+			if err := json.Unmarshal(v, &revisions); err != nil {
+				return fmt.Errorf("decode revisions of %s: %w", k, err)
+			}
+			state.Revisions[string(k)] = revisions
+			return nil
+		}); err != nil {
+			return err
+		}
+		// removed annotations are exported too, so the removal wins over
+		// older copies elsewhere
+		return forEach(tx, bucketAnnotations, func(k, v []byte) error {
+			var a Annotation
+			if err := json.Unmarshal(v, &a); err != nil {
+				return fmt.Errorf("decode annotation of %s: %w", k, err)
+			}
+			state.Annotations[string(k)] = a
+			return nil
+		})
+	})
+	if state.Notices == nil {
+		state.Notices = []Notice{}
+	}
+	return state, err
+}
+
+// ImportState merges s into the store. Notices, seen notices and revisions
+// are united; of reading a notice and marking it unread, and of two
+// versions of an annotation, the later one wins.
+//
+// Notices the store pruned stay pruned: notices of the state dated on or
+// before the prune date of their category in the store, which it does not
+// hold, are skipped.
+// Their seen entries are still merged, so they are not announced again.
+// Read times, revisions and annotations are only merged for notices the
+// store holds, so stars and notes removed before their notices were pruned
+// do not come back either. Machines pruning differently may therefore hold
+// different notices after exchanging their states.
+//
+// With replace, the notices and everything known about them are deleted
+// first, along with the HTTP validators, so the next check fetches the
+// listing in full.
+func (s *BoltStore) ImportState(state State, replace bool) (ImportStats, error) {
+	var stats ImportStats
+	if state.Format > stateFormat {
+		return stats, fmt.Errorf("state has format %d, this version reads up to %d; upgrade aiub-notice", state.Format, stateFormat)
+	}
+	err := s.update(func(tx *bolt.Tx) error {
+		stats = ImportStats{}
+		if replace {
+			if err := clearState(tx); err != nil {
+				return err
+			}
+		}
+		// The prune date is the date of the newest pruned notice, so
+		// notices dated on it may have been pruned as well.
+		pruned, err := pruneDates(tx)
+		if err != nil {
+			return err
+		}
+		if err := mergePruneDates(tx, state.PrunedBefore); err != nil {
+			return err
+		}
+
+		var changed []Notice
+		for _, n := range state.Notices {
+			if n.ID == "" {
+				return fmt.Errorf("import notice %q: missing ID", n.Link)
+			}
+			local, ok, err := getNotice(tx, n.ID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				n = mergeNotices(local, n)
+				if sameJSON(n, local) {
+					continue
+				}
+			} else if before, ok := pruned[n.category()]; ok && !n.Date.IsZero() && !n.Date.After(before) {
+				continue
+			}
+			changed = append(changed, n)
+		}
+		if err := putNotices(tx, changed); err != nil {
+			return err
+		}
+		stats.Notices = len(changed)
+		stored := func(id string) (bool, error) {
+			_, ok, err := getNotice(tx, id)
+			return ok, err
+		}
+
+		seen := tx.Bucket(bucketSeen)
+		for _, id := range state.Seen {
+			if seen.Get([]byte(id)) != nil {
+				continue
+			}
+			if err := seen.Put([]byte(id), []byte{}); err != nil {
+				return err
+			}
+			stats.Seen++
+		}
+
+		read, unread := tx.Bucket(bucketRead), tx.Bucket(bucketUnread)
+		marked := make(map[string]struct{}, len(state.Read)+len(state.Unread))
+		for id := range state.Read {
+			marked[id] = struct{}{}
+		}
+		for id := range state.Unread {
+			marked[id] = struct{}{}
+		}
+		for id := range marked {
+			if ok, err := stored(id); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			localRead, err := markTime(read, id)
+			if err != nil {
+				return err
+			}
+			localUnread, err := markTime(unread, id)
+			if err != nil {
+				return err
+			}
+			readAt, unreadAt := later(localRead, state.Read[id]), later(localUnread, state.Unread[id])
+			if unreadAt.After(readAt) {
+				readAt = time.Time{}
+			} else {
+				unreadAt = time.Time{}
+			}
+			if readAt.Equal(localRead) && unreadAt.Equal(localUnread) {
+				continue
+			}
+			if readAt.IsZero() != localRead.IsZero() {
+				stats.Read++
+			}
+			if err := putMark(read, id, readAt); err != nil {
+				return err
+			}
+			if err := putMark(unread, id, unreadAt); err != nil {
+				return err
+			}
+		}
+
+		revisions := tx.Bucket(bucketRevisions)
+		for id, remote := range state.Revisions {
+			if ok, err := stored(id); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			var local []Revision
+			if data := revisions.Get([]byte(id)); data != nil {
+				if err := json.Unmarshal(data, &local); err != nil {
+					return fmt.Errorf("decode revisions of %s: %w", id, err)
+				}
+			}
+			merged := mergeRevisions(local, remote)
+			if len(merged) == len(local) {
+				continue
+			}
+			data, err := json.Marshal(merged)
+			if err != nil {
+				return fmt.Errorf("encode revisions of %s: %w", id, err)
+			}
+			if err := revisions.Put([]byte(id), data); err != nil {
+				return err
+			}
+			stats.Revisions += len(merged) - len(local)
+		}
+
+		annotations := tx.Bucket(bucketAnnotations)
+		for id, a := range state.Annotations {
+			if ok, err := stored(id); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+			if data := annotations.Get([]byte(id)); data != nil {
+				var local Annotation
+				if err := json.Unmarshal(data, &local); err != nil {
+					return fmt.Errorf("decode annotation of %s: %w", id, err)
+				}
+				if !laterAnnotation(a, local) {
+					continue
+				}
+			}
+			data, err := json.Marshal(a)
+			if err != nil {
+				return fmt.Errorf("encode annotation of %s: %w", id, err)
+			}
+			if err := annotations.Put([]byte(id), data); err != nil {
+				return err
+			}
+			stats.Annotations++
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// forEach calls fn for each key of the named bucket, which may not exist
+// in a store not yet migrated.
+func forEach(tx *bolt.Tx, name []byte, fn func(k, v []byte) error) error {
+	b := tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(fn)
+}
+
+// clearState empties the buckets holding notices and what is known about
+// them.
+func clearState(tx *bolt.Tx) error {
+	for _, name := range [][]byte{
+		bucketNotices, bucketByDate, bucketSeen, bucketRead, bucketUnread, bucketRevisions, bucketAnnotations, bucketSearchIndex,
+	} {
+		if err := tx.DeleteBucket(name); err != nil {
+			return fmt.Errorf("clear bucket %s: %w", name, err)
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return fmt.Errorf("create bucket %s: %w", name, err)
+		}
+	}
+	meta := tx.Bucket(bucketMeta)
+	for _, key := range []string{metaPruned, metaValidators} {
+		if err := meta.Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneDates returns the prune date of each category of the store; see
+// SeenSet.
+func pruneDates(tx *bolt.Tx) (map[string]time.Time, error) {
+	meta := tx.Bucket(bucketMeta)
+	if meta == nil {
+		return make(map[string]time.Time), nil
+	}
+	data := meta.Get([]byte(metaPruned))
+	if data == nil {
+		return make(map[string]time.Time), nil
+	}
+	return decodePruneDates(data, func() ([]string, error) {
+		return storeCategories(tx)
+	})
+}
+
+// putPruneDates replaces the prune dates of the store.
+func putPruneDates(tx *bolt.Tx, dates map[string]time.Time) error {
+	data, err := json.Marshal(dates)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", metaPruned, err)
+	}
+	return tx.Bucket(bucketMeta).Put([]byte(metaPruned), data)
+}
+
+// mergePruneDates moves the prune date of each category of the store
+// forward to its date in remote.
+func mergePruneDates(tx *bolt.Tx, remote map[string]time.Time) error {
+	dates, err := pruneDates(tx)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for category, at := range remote {
+		if at.After(dates[category]) {
+			dates[category] = at
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return putPruneDates(tx, dates)
+}
+
+// decodePruneDates decodes the prune dates in data. Stores and states
+// written before the dates were kept per category hold a single date,
+// which is taken to apply to the categories returned by legacy.
+func decodePruneDates(data []byte, legacy func() ([]string, error)) (map[string]time.Time, error) {
+	var dates map[string]time.Time
+	if err := json.Unmarshal(data, &dates); err == nil {
+		if dates == nil {
+			dates = make(map[string]time.Time)
+		}
+		return dates, nil
+	}
+
+	var at time.Time
+	if err := json.Unmarshal(data, &at); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", metaPruned, err)
+	}
+	dates = make(map[string]time.Time)
+	if at.IsZero() {
+		return dates, nil
+	}
+	categories, err := legacy()
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		dates[category] = at
+	}
+	return dates, nil
+}
+
+// storeCategories returns the primed categories of the store, or the
+// categories of its notices if none were recorded yet.
+func storeCategories(tx *bolt.Tx) ([]string, error) {
+	if data := tx.Bucket(bucketMeta).Get([]byte(metaPrimed)); data != nil {
+		var categories []string
+		if err := json.Unmarshal(data, &categories); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", metaPrimed, err)
+		}
+		return categories, nil
+	}
+	notices, err := noticesSince(tx, time.Time{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return noticeCategories(notices), nil
+}
+
+// noticeCategories returns the categories of notices, sorted.
+func noticeCategories(notices []Notice) []string {
+	var categories []string
+	for _, n := range notices {
+		categories = append(categories, n.category())
+	}
+	sort.Strings(categories)
+	return slices.Compact(categories)
+}
+
+// mergeNotices combines two copies of a notice kept by different machines.
+// The copy found on the site most recently wins; the first sighting is the
+// earlier of both, and details fetched only by the other machine are kept.
+func mergeNotices(a, b Notice) Notice {
+	newer, older := a, b
+	if b.LastSeen.After(a.LastSeen) || (b.LastSeen.Equal(a.LastSeen) && b.Hash > a.Hash) {
+		newer, older = b, a
+	}
+	if !older.FirstSeen.IsZero() && (newer.FirstSeen.IsZero() || older.FirstSeen.Before(newer.FirstSeen)) {
+		newer.FirstSeen = older.FirstSeen
+	}
+	if !newer.HasDetail() && older.HasDetail() && newer.Title == older.Title && newer.Desc == older.Desc {
+		newer.setDetail(older.detail())
+	}
+	return newer
+}
+
+// mergeRevisions unites two revision histories of a notice, oldest first.
+func mergeRevisions(local, remote []Revision) []Revision {
+	type key struct {
+		hash string
+		at   int64
+	}
+	merged := append([]Revision(nil), local...)
+	known := make(map[key]struct{}, len(local))
+	for _, r := range local {
+		known[key{r.Hash, r.RecordedAt.UnixNano()}] = struct{}{}
+	}
+	for _, r := range remote {
+		k := key{r.Hash, r.RecordedAt.UnixNano()}
+		if _, ok := known[k]; ok {
+			continue
+		}
+		known[k] = struct{}{}
+		merged = append(merged, r)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].RecordedAt.Before(merged[j].RecordedAt)
+	})
+	return merged
+}
+
+// markTime returns the time b holds for id, which is zero if it holds
+// none.
+func markTime(b *bolt.Bucket, id string) (time.Time, error) {
+	var at time.Time
+	if data := b.Get([]byte(id)); data != nil {
+		if err := at.UnmarshalBinary(data); err != nil {
+			return at, fmt.Errorf("decode read state of %s: %w", id, err)
+		}
+	}
+	return at, nil
+}
+
+// putMark stores at for id in b, or deletes the entry of id if at is zero.
+func putMark(b *bolt.Bucket, id string, at time.Time) error {
+	if at.IsZero() {
+		return b.Delete([]byte(id))
+	}
+	value, err := at.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(id), value)
+}
+
+// later returns the later of a and b.
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// laterAnnotation reports whether a replaces b when merging. Ties are
+// broken by content so that both machines pick the same version.
+func laterAnnotation(a, b Annotation) bool {
+	if !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return a.UpdatedAt.After(b.UpdatedAt)
+	}
+	if a.Note != b.Note {
+		return a.Note > b.Note
+	}
+	return a.StarredAt.After(b.StarredAt)
+}
+
+// sameJSON reports whether a and b encode to the same JSON.
+func sameJSON(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
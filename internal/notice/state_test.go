@@ -0,0 +1,338 @@
+package notice
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_BoltStore_ImportState(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	laptop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	desktop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+
+	shared, onlyLaptop, onlyDesktop := testNotice("/shared", day(1)), testNotice("/laptop", day(2)), testNotice("/desktop", day(3))
+	sharedLater := shared
+	sharedLater.FirstSeen, sharedLater.LastSeen = day(2), day(5)
+	sharedLater.Body = "details fetched by the desktop"
+	shared.FirstSeen, shared.LastSeen = day(1), day(4)
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(laptop.PutNotices(shared, onlyLaptop))
+	must(laptop.MarkSeen(shared.ID, onlyLaptop.ID))
+	must(laptop.SetRead(day(6), shared.ID))
+	must(laptop.SetAnnotation(onlyLaptop.ID, Annotation{StarredAt: day(6), UpdatedAt: day(6)}))
+	must(laptop.SetAnnotation(shared.ID, Annotation{Note: "old note", UpdatedAt: day(6)}))
+	must(laptop.SetRevisions(shared.ID, []Revision{{Hash: "a", RecordedAt: day(1)}}))
+
+	must(desktop.PutNotices(sharedLater, onlyDesktop))
+	must(desktop.MarkSeen(shared.ID, onlyDesktop.ID))
+	must(desktop.SetRead(day(7), shared.ID, onlyDesktop.ID))
+	// the note was removed on the desktop after it was written on the laptop
+	must(desktop.SetAnnotation(shared.ID, Annotation{UpdatedAt: day(7)}))
+	must(desktop.SetRevisions(shared.ID, []Revision{{Hash: "a", RecordedAt: day(1)}, {Hash: "b", RecordedAt: day(3)}}))
+
+	exportState := func(s *BoltStore) State {
+		t.Helper()
+		state, err := s.ExportState()
+		must(err)
+		return state
+	}
+	laptopState, desktopState := exportState(laptop), exportState(desktop)
+	stats, err := laptop.ImportState(desktopState, false)
+	must(err)
+	if stats.Notices != 2 || stats.Seen != 1 || stats.Read != 1 || stats.Revisions != 1 || stats.Annotations != 1 {
+		t.Errorf("merge stats = %+v", stats)
+	}
+	_, err = desktop.ImportState(laptopState, false)
+	must(err)
+
+	// Both machines agree whatever the order of the merges.
+	var a, b bytes.Buffer
+	must(WriteState(&a, exportState(laptop)))
+	must(WriteState(&b, exportState(desktop)))
+	if a.String() != b.String() {
+		t.Errorf("merged states differ:\n%s\n%s", a.String(), b.String())
+	}
+
+	if count, _ := laptop.CountNotices(); count != 3 {
+		t.Errorf("CountNotices() = %d after merging", count)
+	}
+	merged, err := laptop.Notice(shared.ID)
+	must(err)
+	if !merged.FirstSeen.Equal(day(1)) || !merged.LastSeen.Equal(day(5)) || merged.Body == "" {
+		t.Errorf("merged notice = %+v", merged)
+	}
+	if read, _ := laptop.Read(); !read[shared.ID].Equal(day(7)) || len(read) != 2 {
+		t.Errorf("Read() = %v, want the latest read time", read)
+	}
+	if annotations, _ := laptop.Annotations(); len(annotations) != 1 || !annotations[onlyLaptop.ID].Starred() {
+		t.Errorf("Annotations() = %v, want the removed note to stay removed", annotations)
+	}
+	if revisions, _ := laptop.Revisions(shared.ID); len(revisions) != 2 {
+		t.Errorf("Revisions() = %v", revisions)
+	}
+
+	// Merging again changes nothing.
+	stats, err = laptop.ImportState(desktopState, false)
+	must(err)
+	if !stats.IsZero() {
+		t.Errorf("second merge stats = %+v", stats)
+	}
+
+	// Replacing drops what the imported state does not have.
+	stats, err = laptop.ImportState(laptopState, true)
+	must(err)
+	if count, _ := laptop.CountNotices(); count != 2 || stats.Notices != 2 {
+		t.Errorf("CountNotices() = %d after replacing, stats = %+v", count, stats)
+	}
+	if _, err := laptop.Notice(onlyDesktop.ID); err == nil {
+		t.Errorf("notice of the desktop kept after replacing")
+	}
+
+	if _, err := laptop.ImportState(State{Format: stateFormat + 1}, false); err == nil {
+		t.Errorf("ImportState() accepted a newer format")
+	}
+}
+
+func Test_BoltStore_ImportState_pruned(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	laptop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	desktop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old, sameDay, kept := testNotice("/old", day(1)), testNotice("/same-day", day(2)), testNotice("/kept", day(9))
+	all := []Notice{old, sameDay, kept}
+	for _, s := range []*BoltStore{laptop, desktop} {
+		must(s.PutNotices(all...))
+		must(s.MarkSeen(old.ID, sameDay.ID, kept.ID))
+		must(s.SetRead(day(5), old.ID))
+		must(s.SetRevisions(sameDay.ID, []Revision{{Hash: "a", RecordedAt: day(3)}}))
+		must(s.SetAnnotation(old.ID, Annotation{Note: "bring the receipt", UpdatedAt: day(5)}))
+	}
+	// A notice dated on the prune day only reached the desktop.
+	late := testNotice("/late", day(2))
+	must(desktop.PutNotices(late))
+	must(desktop.MarkSeen(late.ID))
+	// The note is removed on the laptop, which then prunes both old notices.
+	must(laptop.SetAnnotation(old.ID, Annotation{UpdatedAt: day(6)}))
+	if _, err := Prune(laptop, RetentionPolicy{MaxNotices: 1}, day(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	desktopState, err := desktop.ExportState()
+	must(err)
+	stats, err := laptop.ImportState(desktopState, false)
+	must(err)
+	if want := (ImportStats{Seen: 2}); stats != want {
+		t.Errorf("import stats = %+v, want only seen entries %+v", stats, want)
+	}
+	if count, _ := laptop.CountNotices(); count != 1 {
+		t.Errorf("CountNotices() = %d, want the pruned notices to stay pruned", count)
+	}
+	if annotations, _ := laptop.Annotations(); len(annotations) != 0 {
+		t.Errorf("Annotations() = %v, want the removed note to stay removed", annotations)
+	}
+	if read, _ := laptop.Read(); len(read) != 0 {
+		t.Errorf("Read() = %v", read)
+	}
+	if ids, _ := laptop.RevisedNotices(); len(ids) != 0 {
+		t.Errorf("RevisedNotices() = %v", ids)
+	}
+	// Seen entries are merged even for skipped notices, so a notice dated on
+	// the prune day, which does not count as pruned, is not announced.
+	laptopSeen, err := LoadSeen(laptop)
+	must(err)
+	for _, n := range []Notice{old, sameDay, late, kept} {
+		if !laptopSeen.Has(n) {
+			t.Errorf("%s is not seen after the import", n.Link)
+		}
+	}
+
+	// The desktop learns the prune date, so the pruned notices count as seen there.
+	laptopState, err := laptop.ExportState()
+	must(err)
+	_, err = desktop.ImportState(laptopState, false)
+	must(err)
+	seen, err := LoadSeen(desktop)
+	must(err)
+	if got := seen.PrunedBefore[DefaultSource.Category]; !got.Equal(day(2)) {
+		t.Errorf("PrunedBefore = %s on the desktop, want %s", got, day(2))
+	}
+
+	// Replacing the state imports it whole.
+	fresh := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	must(fresh.PutNotices(kept))
+	_, err = fresh.ImportState(desktopState, true)
+	must(err)
+	if count, _ := fresh.CountNotices(); count != 4 {
+		t.Errorf("CountNotices() = %d after replacing", count)
+	}
+}
+
+func Test_BoltStore_ImportState_unread(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	laptop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	desktop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	exportState := func(s *BoltStore) State {
+		t.Helper()
+		state, err := s.ExportState()
+		must(err)
+		return state
+	}
+	// exchange merges the states of both machines into each other.
+	exchange := func() {
+		t.Helper()
+		laptopState, desktopState := exportState(laptop), exportState(desktop)
+		_, err := laptop.ImportState(desktopState, false)
+		must(err)
+		_, err = desktop.ImportState(laptopState, false)
+		must(err)
+	}
+	isRead := func(s *BoltStore, n Notice) bool {
+		t.Helper()
+		read, err := s.Read()
+		must(err)
+		_, ok := read[n.ID]
+		return ok
+	}
+
+	exam, fees := testNotice("/exam", day(1)), testNotice("/fees", day(2))
+	for _, s := range []*BoltStore{laptop, desktop} {
+		must(s.PutNotices(exam, fees))
+		must(s.SetRead(day(5), exam.ID, fees.ID))
+	}
+
+	// Marking a notice unread is not undone by the older read time elsewhere.
+	must(laptop.SetRead(time.Time{}, exam.ID))
+	exchange()
+	exchange()
+	for name, s := range map[string]*BoltStore{"laptop": laptop, "desktop": desktop} {
+		if isRead(s, exam) {
+			t.Errorf("notice marked unread is read again on the %s", name)
+		}
+		if !isRead(s, fees) {
+			t.Errorf("other notice is no longer read on the %s", name)
+		}
+	}
+
+	// Reading it again later wins over the unread mark.
+	must(desktop.SetRead(time.Now().Add(time.Hour), exam.ID))
+	exchange()
+	if !isRead(laptop, exam) || !isRead(desktop, exam) {
+		t.Errorf("notice read again after it was marked unread is not read on both machines")
+	}
+
+	var a, b bytes.Buffer
+	must(WriteState(&a, exportState(laptop)))
+	must(WriteState(&b, exportState(desktop)))
+	if a.String() != b.String() {
+		t.Errorf("merged states differ:\n%s\n%s", a.String(), b.String())
+	}
+}
+
+func Test_ReadState_pruneDates(t *testing.T) {
+	day := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		json string
+		want map[string]time.Time
+	}{
+		{
+			name: "per category",
+			json: `{"format":1,"notices":[],"seen":[],"pruned_before":{"News":"2025-03-05T00:00:00Z"}}`,
+			want: map[string]time.Time{"News": day},
+		},
+		{
+			name: "single date of older versions applies to the categories of the notices",
+			json: `{"format":1,"notices":[{"ID":"a","Category":"News"},{"ID":"b"}],"seen":[],"pruned_before":"2025-03-05T00:00:00Z"}`,
+			want: map[string]time.Time{"News": day, DefaultSource.Category: day},
+		},
+		{
+			name: "nothing pruned",
+			json: `{"format":1,"notices":[],"seen":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := ReadState(strings.NewReader(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(state.PrunedBefore) != len(tt.want) || (len(tt.want) > 0 && !reflect.DeepEqual(state.PrunedBefore, tt.want)) {
+				t.Errorf("PrunedBefore = %v, want %v", state.PrunedBefore, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Syncer(t *testing.T) {
+	dir := t.TempDir()
+	laptop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	desktop := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	n := testNotice("/fees", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err := laptop.PutNotices(n); err != nil {
+		t.Fatal(err)
+	}
+	if err := laptop.MarkSeen(n.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	laptopSync, desktopSync := NewSyncer(laptop, dir), NewSyncer(desktop, dir)
+	if err := laptopSync.Push(); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := desktopSync.Pull()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Notices != 1 || stats.Seen != 1 {
+		t.Errorf("Pull() = %+v", stats)
+	}
+	seen, err := LoadSeen(desktop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !seen.Has(n) {
+		t.Errorf("notice seen on the laptop is not seen on the desktop")
+	}
+
+	// An unchanged file is not merged again, and the own file never is.
+	if err := desktopSync.Push(); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err := desktopSync.Pull(); err != nil || !stats.IsZero() {
+		t.Errorf("second Pull() = %+v, %v", stats, err)
+	}
+	if stats, err := laptopSync.Pull(); err != nil || !stats.IsZero() {
+		t.Errorf("Pull() of an identical state = %+v, %v", stats, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, syncFilePrefix+"*.json"))
+	if len(files) != 2 {
+		t.Errorf("sync folder holds %v", files)
+	}
+
+	if _, err := NewSyncer(laptop, filepath.Join(dir, "missing")).Pull(); err == nil {
+		t.Errorf("Pull() from a missing folder succeeded")
+	}
+}
@@ -0,0 +1,138 @@
+package notice
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+// ErrNotFound is returned when a requested notice is not in the store.
+var ErrNotFound = errors.New("notice not found")
+
+// Store persists notices and what is known about them: which were seen and
+// read, their revisions, the user's stars and notes, and metadata such as
+// HTTP validators.
+type Store interface {
+	// Notices returns all stored notices, newest first.
+	Notices() ([]Notice, error)
+	// NoticesSince returns the notices dated at or after since, newest first.
+	NoticesSince(since time.Time) ([]Notice, error)
+	// Notice returns the notice with the given ID, or ErrNotFound.
+	Notice(id string) (Notice, error)
+	// CountNotices returns the number of stored notices.
+	CountNotices() (int, error)
+	// PutNotices inserts notices or replaces those with the same ID.
+	PutNotices(notices ...Notice) error
+	// PruneNotices deletes notices along with their read state, revisions
+	// and annotations. Their seen state is kept as the date of the newest
+	// pruned notice of each category, see SeenSet.
+	PruneNotices(ids ...string) error
+
+	// Seen returns the IDs of the notices that were already announced or
+	// deliberately skipped.
+	Seen() (map[string]struct{}, error)
+	// MarkSeen adds notices to the seen set.
+	MarkSeen(ids ...string) error
+	// PruneDates returns the date of the newest pruned notice of each
+	// category.
+	PruneDates() (map[string]time.Time, error)
+
+	// Read returns when each read notice was read, by ID.
+	Read() (map[string]time.Time, error)
+	// SetRead marks notices as read at the given time, or as unread if at
+	// is zero. Notices marked unread are recorded with the current time, so
+	// the change wins over older read times when states are merged.
+	SetRead(at time.Time, ids ...string) error
+	// Unread returns the unread notices dated at or after since, newest
+	// first.
+	Unread(since time.Time) ([]Notice, error)
+
+	// Revisions returns the recorded revisions of a notice, oldest first.
+	Revisions(id string) ([]Revision, error)
+	// SetRevisions replaces the recorded revisions of a notice.
+	SetRevisions(id string, revisions []Revision) error
+	// RevisedNotices returns the IDs of the notices with revisions.
+	RevisedNotices() ([]string, error)
+
+	// Annotations returns the annotations of all annotated notices, by ID.
+	Annotations() (map[string]Annotation, error)
+	// Annotation returns the annotation of a notice, which is zero if the
+	// notice has none.
+	Annotation(id string) (Annotation, error)
+	// SetAnnotation replaces the annotation of a notice. A zero annotation
+	// removes it; if it has an update time, the removal is remembered so
+	// that importing an older state does not bring the annotation back.
+	SetAnnotation(id string, a Annotation) error
+
+	// Search returns the notices matching q, best matches first.
+	Search(q Query) ([]SearchResult, error)
+
+	// Meta decodes the metadata stored under key into v and reports whether
+	// the key exists.
+	Meta(key string, v any) (bool, error)
+	// SetMeta stores v as JSON under key.
+	SetMeta(key string, v any) error
+
+	// Backup saves a copy of the current state, rotating older copies.
+	Backup() error
+	// Compact returns the space left free by deleted data to the file
+	// system.
+	Compact() error
+
+	// ExportState returns everything the store knows about notices.
+	ExportState() (State, error)
+	// ImportState merges s into the store, or replaces the state of the
+	// store with it if replace is set, and reports what changed.
+	ImportState(s State, replace bool) (ImportStats, error)
+}
+
+// storeFileName is the database file in the data directory.
+const storeFileName = "notices.db"
+
+// imported records the store paths already checked for damage and for JSON
+// files to import in this process.
+var imported sync.Map
+
+// DefaultStore returns the store in the data directory. The first time it
+// is used, a damaged store is restored from its backups and the JSON files
+// written by older versions are imported into it.
+func DefaultStore() (Store, error) {
+	dir, err := common.GetDataPath()
+	if err != nil {
+		return nil, fmt.Errorf("get data path: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	store := OpenStore(filepath.Join(dir, storeFileName))
+
+	if _, done := imported.Load(store.path); !done {
+		if err := recoverStore(store); err != nil {
+			return nil, err
+		}
+		if err := importJSONState(dir, store); err != nil {
+			return nil, fmt.Errorf("import JSON state: %w", err)
+		}
+		imported.Store(store.path, true)
+	}
+	return store, nil
+}
+
+// OpenStore returns the store kept in the database file at path, which is
+// created on first write. The file is only opened for the duration of each
+// operation, so the service and CLI commands can share it; an operation
+// waits for the other process to finish its own.
+func OpenStore(path string) *BoltStore {
+	return &BoltStore{path: path}
+}
+
+// storeExists reports whether the database file at path exists.
+func storeExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
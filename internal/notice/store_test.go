@@ -0,0 +1,242 @@
+package notice
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_BoltStore(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+
+	if notices, err := store.Notices(); err != nil || len(notices) != 0 {
+		t.Fatalf("empty store: notices = %v, err = %v", notices, err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	notice := func(path string, date time.Time) Notice {
+		link := "https://www.aiub.edu" + path
+		return Notice{ID: NoticeID(link), Link: link, Title: path, Date: date}
+	}
+	older, newer, undated := notice("/older", day(1)), notice("/newer", day(5)), notice("/undated", time.Time{})
+	if err := store.PutNotices(older, undated, newer); err != nil {
+		t.Fatal(err)
+	}
+
+	titles := func(notices []Notice) []string {
+		var titles []string
+		for _, n := range notices {
+			titles = append(titles, n.Title)
+		}
+		return titles
+	}
+	all, err := store.Notices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(all); len(got) != 3 || got[0] != "/newer" || got[2] != "/undated" {
+		t.Errorf("Notices() = %v, want newest first", got)
+	}
+	if got, _ := store.NoticesSince(day(2)); len(got) != 1 || got[0].ID != newer.ID {
+		t.Errorf("NoticesSince() = %v", titles(got))
+	}
+
+	// Replacing a notice moves it in the date index.
+	older.Date = day(9)
+	if err := store.PutNotices(older); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := store.CountNotices(); count != 3 {
+		t.Errorf("CountNotices() = %d after replacing a notice", count)
+	}
+	if got, _ := store.NoticesSince(day(2)); len(got) != 2 || got[0].ID != older.ID {
+		t.Errorf("NoticesSince() = %v after redating", titles(got))
+	}
+
+	if _, err := store.Notice("0123456789abcdef"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Notice() of a missing ID: err = %v", err)
+	}
+
+	if err := store.SetRead(day(10), older.ID, newer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetRead(time.Time{}, newer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if unread, _ := store.Unread(time.Time{}); len(unread) != 2 || unread[0].ID != newer.ID {
+		t.Errorf("Unread() = %v", titles(unread))
+	}
+	if read, _ := store.Read(); len(read) != 1 || !read[older.ID].Equal(day(10)) {
+		t.Errorf("Read() = %v", read)
+	}
+
+	if found, err := store.Meta("missing", new(int)); found || err != nil {
+		t.Errorf("Meta() of a missing key: found = %t, err = %v", found, err)
+	}
+	if err := store.SetMeta("answer", 42); err != nil {
+		t.Fatal(err)
+	}
+	var answer int
+	if found, err := store.Meta("answer", &answer); !found || err != nil || answer != 42 {
+		t.Errorf("Meta() = %d, %t, %v", answer, found, err)
+	}
+}
+
+func Test_importJSONState(t *testing.T) {
+	dataPath := withTempDataDir(t)
+
+	link := "https://www.aiub.edu/holiday-notice"
+	write := func(name string, v any) {
+		t.Helper()
+		data, _ := json.Marshal(v)
+		if err := os.WriteFile(filepath.Join(dataPath, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	migrated := NoticeID("https://www.aiub.edu/already-migrated")
+	write(legacyNoticesFile, []Notice{{Link: link, Title: "Holiday", Date: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}})
+	write(legacySeenFile, map[string]struct{}{
+		link:       {},
+		link + "/": {},
+		migrated:   {},
+	})
+	write(legacyBackfillFile, map[string]BackfillState{"Notices": {NextPage: 4}})
+
+	store, err := DefaultStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen, err := store.Seen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{NoticeID(link), migrated}
+	if len(seen) != len(want) {
+		t.Fatalf("seen = %v, want %v", seen, want)
+	}
+	for _, id := range want {
+		if _, ok := seen[id]; !ok {
+			t.Errorf("missing %s in %v", id, seen)
+		}
+	}
+
+	if unread, _ := store.Unread(time.Time{}); len(unread) != 0 {
+		t.Errorf("announced legacy notices imported as unread: %v", unread)
+	}
+
+	n, err := store.Notice(NoticeID(link))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Hash == "" || !n.FirstSeen.Equal(n.Date) {
+		t.Errorf("legacy fields not filled: %+v", n)
+	}
+	if state, _ := LoadBackfillState("Notices"); state.NextPage != 4 {
+		t.Errorf("backfill state = %+v", state)
+	}
+
+	for _, name := range []string{legacyNoticesFile, legacySeenFile, legacyBackfillFile} {
+		path := filepath.Join(dataPath, name)
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not renamed after import", name)
+		}
+		if _, err := os.Stat(path + legacyImportedSuffix); err != nil {
+			t.Errorf("%s backup missing: %v", name, err)
+		}
+	}
+}
+
+func Test_recoverStore(t *testing.T) {
+	dir := t.TempDir()
+	store := OpenStore(filepath.Join(dir, storeFileName))
+
+	backedUp := Notice{ID: NoticeID("https://www.aiub.edu/a"), Link: "https://www.aiub.edu/a"}
+	lost := Notice{ID: NoticeID("https://www.aiub.edu/b"), Link: "https://www.aiub.edu/b"}
+	if err := store.PutNotices(backedUp); err != nil {
+		t.Fatal(err)
+	}
+	for range storeBackups + 1 {
+		if err := store.Backup(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(backupPath(store.Path(), storeBackups+1)); !os.IsNotExist(err) {
+		t.Errorf("more than %d backups kept", storeBackups)
+	}
+	if err := store.PutNotices(lost); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recoverStore(store); err != nil {
+		t.Fatalf("intact store: %v", err)
+	}
+
+	damage := func() {
+		t.Helper()
+		if err := os.WriteFile(store.Path(), []byte("not a database"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	damage()
+	if err := recoverStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Notice(backedUp.ID); err != nil {
+		t.Errorf("backed up notice not restored: %v", err)
+	}
+	if _, err := store.Notice(lost.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("notice stored after the backup: err = %v", err)
+	}
+	if matches, _ := filepath.Glob(store.Path() + ".corrupt-*"); len(matches) != 1 {
+		t.Errorf("damaged store not kept aside: %v", matches)
+	}
+
+	for i := 1; i <= storeBackups; i++ {
+		_ = os.Remove(backupPath(store.Path(), i))
+	}
+	damage()
+	if err := recoverStore(store); err == nil {
+		t.Error("damaged store without backups recovered silently")
+	}
+	if storeExists(store.Path()) {
+		t.Error("damaged store left in place")
+	}
+}
+
+func Test_BoltStore_annotations(t *testing.T) {
+	store := OpenStore(filepath.Join(t.TempDir(), storeFileName))
+	n := Notice{ID: NoticeID("https://www.aiub.edu/fees"), Link: "https://www.aiub.edu/fees", Title: "Fees"}
+	if err := store.PutNotices(n); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if err := store.SetAnnotation(n.ID, Annotation{StarredAt: now, Note: "pay by Friday", UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refreshing the notice keeps its annotation.
+	n.Title = "Fees (revised)"
+	if err := store.PutNotices(n); err != nil {
+		t.Fatal(err)
+	}
+	a, err := store.Annotation(n.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Starred() || a.Note != "pay by Friday" {
+		t.Errorf("annotation after refresh = %+v", a)
+	}
+
+	a.StarredAt, a.Note = time.Time{}, ""
+	if err := store.SetAnnotation(n.ID, a); err != nil {
+		t.Fatal(err)
+	}
+	if annotations, _ := store.Annotations(); len(annotations) != 0 {
+		t.Errorf("empty annotation kept: %v", annotations)
+	}
+}
@@ -0,0 +1,158 @@
+package notice
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+// metaMachine is the metadata key of the ID naming the state file of this
+// machine in a sync folder.
+const metaMachine = "machine"
+
+// syncFilePrefix starts the names of the state files in a sync folder.
+const syncFilePrefix = "aiub-notice-"
+
+// Syncer shares the state of a store with other machines through a folder
+// that is synchronized between them, such as a Syncthing folder. Each
+// machine only writes its own state file and merges the files of the
+// others, so no file is ever written by two machines. Merging never drops
+// data, though notices a machine has pruned are not brought back to it.
+type Syncer struct {
+	store Store
+	dir   string
+	// merged holds the modification times of the files merged so far, so
+	// unchanged files are not merged again.
+	merged map[string]time.Time
+}
+
+// NewSyncer returns a Syncer sharing the state of store through dir.
+func NewSyncer(store Store, dir string) *Syncer {
+	return &Syncer{store: store, dir: dir, merged: make(map[string]time.Time)}
+}
+
+// Dir returns the sync folder.
+func (s *Syncer) Dir() string { return s.dir }
+
+// Pull merges the state files of the other machines into the store. Files
+// that cannot be read are skipped and reported in the returned error.
+func (s *Syncer) Pull() (ImportStats, error) {
+	var total ImportStats
+	own, err := s.ownFile()
+	if err != nil {
+		return total, err
+	}
+	files, err := filepath.Glob(filepath.Join(s.dir, syncFilePrefix+"*.json"))
+	if err != nil {
+		return total, err
+	}
+
+	var errs []error
+	for _, path := range files {
+		// Syncthing keeps conflicting copies of a file next to it; they
+		// are merged like any other file, including copies of our own.
+		if path == own {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if mod, ok := s.merged[path]; ok && mod.Equal(info.ModTime()) {
+			continue
+		}
+
+		stats, err := s.pullFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("merge %s: %w", filepath.Base(path), err))
+			continue
+		}
+		s.merged[path] = info.ModTime()
+		total.add(stats)
+	}
+	return total, errors.Join(errs...)
+}
+
+func (s *Syncer) pullFile(path string) (ImportStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ImportStats{}, err
+	}
+	defer func() { _ = f.Close() }()
+	state, err := ReadState(f)
+	if err != nil {
+		return ImportStats{}, err
+	}
+	return s.store.ImportState(state, false)
+}
+
+// Push writes the state of the store to the state file of this machine. The
+// file is left alone if the state did not change, so the sync tool has
+// nothing to transfer.
+func (s *Syncer) Push() error {
+	own, err := s.ownFile()
+	if err != nil {
+		return err
+	}
+	state, err := s.store.ExportState()
+	if err != nil {
+		return fmt.Errorf("export state: %w", err)
+	}
+	state.Machine, _ = os.Hostname()
+
+	var buf bytes.Buffer
+	if err := WriteState(&buf, state); err != nil {
+		return err
+	}
+	if old, err := os.ReadFile(own); err == nil && bytes.Equal(old, buf.Bytes()) {
+		return nil
+	}
+	if err := common.WriteFileAtomic(own, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	return nil
+}
+
+// ownFile returns the state file of this machine, checking that the sync
+// folder exists.
+func (s *Syncer) ownFile() (string, error) {
+	info, err := os.Stat(s.dir)
+	if err != nil {
+		return "", fmt.Errorf("sync folder: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("sync folder %s is not a directory", s.dir)
+	}
+	id, err := machineID(s.store)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, syncFilePrefix+id+".json"), nil
+}
+
+// machineID returns the random ID of this machine, created on first use.
+// A host name is not used, as it may change or be shared by machines.
+func machineID(store Store) (string, error) {
+	var id string
+	if found, err := store.Meta(metaMachine, &id); err != nil {
+		return "", fmt.Errorf("load machine ID: %w", err)
+	} else if found && id != "" && !strings.ContainsAny(id, `/\.`) {
+		return id, nil
+	}
+
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	id = hex.EncodeToString(b)
+	if err := store.SetMeta(metaMachine, id); err != nil {
+		return "", fmt.Errorf("save machine ID: %w", err)
+	}
+	return id, nil
+}
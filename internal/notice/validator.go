@@ -0,0 +1,152 @@
+package notice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// FetchStatus describes what a conditional fetch found.
+type FetchStatus string
+
+const (
+	// FetchChanged means the response was new and has been parsed.
+	FetchChanged FetchStatus = "changed"
+	// FetchNotModified means the server answered 304 Not Modified.
+	FetchNotModified FetchStatus = "not_modified"
+	// FetchUnchanged means the server sent the same body as last time.
+	FetchUnchanged FetchStatus = "unchanged"
+)
+
+// Validator holds the HTTP cache validators and body hash of the last
+// successfully processed response for a URL.
+type Validator struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	BodyHash     string    `json:"body_hash"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+func loadValidators() (map[string]Validator, error) {
+	validators := make(map[string]Validator)
+
+	store, err := DefaultStore()
+	if err != nil {
+		return validators, err
+	}
+	if _, err := store.Meta(metaValidators, &validators); err != nil {
+		return make(map[string]Validator), err
+	}
+	return validators, nil
+}
+
+// validatorMaxAge is how long the validators of a URL that is no longer
+// fetched, such as the page of an older notice, are kept.
+const validatorMaxAge = 30 * 24 * time.Hour
+
+// storeValidators merges updated validators into the stored ones, dropping
+// validators not used within validatorMaxAge.
+func storeValidators(updated map[string]Validator) error {
+	if len(updated) == 0 {
+		return nil
+	}
+
+	validators, err := loadValidators()
+	if err != nil {
+		// Stale validators only cost a full download; start afresh.
+		validators = make(map[string]Validator)
+	}
+	for url, v := range updated {
+		validators[url] = v
+	}
+	expired := time.Now().Add(-validatorMaxAge)
+	for url, v := range validators {
+		if v.CheckedAt.Before(expired) {
+			delete(validators, url)
+		}
+	}
+
+	store, err := DefaultStore()
+	if err != nil {
+		return err
+	}
+	return store.SetMeta(metaValidators, validators)
+}
+
+// ResetValidators forgets all stored validators, forcing the next check of
+// every source to download and parse its listing.
+func ResetValidators() error {
+	store, err := DefaultStore()
+	if err != nil {
+		return err
+	}
+	return store.SetMeta(metaValidators, map[string]Validator{})
+}
+
+// conditionalGet fetches url, sending the validators stored for it. The body
+// is only returned when the status is FetchChanged; the returned validator
+// must be saved once the body has been processed.
+func conditionalGet(ctx context.Context, url string) ([]byte, Validator, FetchStatus, error) {
+	validators, err := loadValidators()
+	if err != nil {
+		validators = make(map[string]Validator)
+	}
+	previous := validators[url]
+
+	// Without cached notices there is nothing the validators could refer to.
+	if count, err := countCachedNotices(); err != nil || count == 0 {
+		previous = Validator{}
+	}
+
+	header := make(http.Header)
+	if previous.ETag != "" {
+		header.Set("If-None-Match", previous.ETag)
+	}
+	if previous.LastModified != "" {
+		header.Set("If-Modified-Since", previous.LastModified)
+	}
+
+	response, err := httpGetWithHeaders(ctx, url, header, currentRetryPolicy())
+	if err != nil {
+		return nil, previous, "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	now := time.Now()
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		previous.CheckedAt = now
+		return nil, previous, FetchNotModified, nil
+	case http.StatusOK:
+	default:
+		return nil, previous, "", fmt.Errorf("received status code %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, previous, "", fmt.Errorf("read response: %w", err)
+	}
+
+	current := newValidator(response.Header, body)
+	if previous.BodyHash != "" && previous.BodyHash == current.BodyHash {
+		return nil, current, FetchUnchanged, nil
+	}
+
+	return body, current, FetchChanged, nil
+}
+
+// newValidator returns the validator of a response with the given header
+// and body.
+func newValidator(header http.Header, body []byte) Validator {
+	sum := sha256.Sum256(body)
+	return Validator{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		BodyHash:     hex.EncodeToString(sum[:]),
+		CheckedAt:    time.Now(),
+	}
+}
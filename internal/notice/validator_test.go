@@ -0,0 +1,63 @@
+package notice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_conditionalGet(t *testing.T) {
+	withTempDataDir(t)
+	store, err := DefaultStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutNotices(Notice{ID: NoticeID("https://www.aiub.edu/n"), Link: "https://www.aiub.edu/n"}); err != nil {
+		t.Fatalf("create cache: %v", err)
+	}
+
+	body := "page one"
+	etag := `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if etag != "" {
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	steps := []struct {
+		name   string
+		setup  func()
+		status FetchStatus
+	}{
+		{name: "first fetch", status: FetchChanged},
+		{name: "etag matches", status: FetchNotModified},
+		{name: "same body without validators", setup: func() { etag = "" }, status: FetchUnchanged},
+		{name: "new body", setup: func() { body = "page two" }, status: FetchChanged},
+	}
+	for _, step := range steps {
+		if step.setup != nil {
+			step.setup()
+		}
+
+		got, validator, status, err := conditionalGet(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if status != step.status {
+			t.Fatalf("%s: status = %s, want %s", step.name, status, step.status)
+		}
+		if status == FetchChanged && string(got) != body {
+			t.Errorf("%s: body = %q, want %q", step.name, got, body)
+		}
+		if err := storeValidators(map[string]Validator{server.URL: validator}); err != nil {
+			t.Fatalf("%s: store validators: %v", step.name, err)
+		}
+	}
+}
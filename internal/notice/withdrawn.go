@@ -0,0 +1,99 @@
+package notice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/AtifChy/aiub-notice/internal/logger"
+)
+
+// ErrNoticeGone is returned when a notice page answers 404 Not Found or
+// 410 Gone.
+var ErrNoticeGone = errors.New("notice page is gone")
+
+// maxWithdrawnChecks bounds the detail requests made per fetch to confirm
+// that missing notices were withdrawn, in case the listing was reorganised.
+const maxWithdrawnChecks = 10
+
+// findWithdrawn returns the cached notices of src that are missing from the
+// fetched listing pages although they are newer than the oldest notice on
+// them, and whose own pages are gone. Notices older than the fetched pages
+// have merely aged off and are not checked, nor are notices dated the same
+// day as the oldest fetched notice, which may have moved to the next page.
+func findWithdrawn(ctx context.Context, src SourceConfig, fetched []Notice) []Notice {
+	var oldest time.Time
+	listed := make(map[string]struct{}, len(fetched))
+	for _, n := range fetched {
+		listed[n.ID] = struct{}{}
+		if !n.Date.IsZero() && (oldest.IsZero() || n.Date.Before(oldest)) {
+			oldest = n.Date
+		}
+	}
+	if oldest.IsZero() {
+		return nil
+	}
+
+	store, err := DefaultStore()
+	if err != nil {
+		logger.L().Warn("loading cached notices", slog.String("error", err.Error()))
+		return nil
+	}
+	cached, err := store.NoticesSince(oldest)
+	if err != nil {
+		logger.L().Warn("loading cached notices", slog.String("error", err.Error()))
+		return nil
+	}
+
+	var withdrawn []Notice
+	checks := 0
+	for _, n := range cached {
+		if n.Withdrawn() || !n.Date.After(oldest) || !src.owns(n) {
+			continue
+		}
+		if _, ok := listed[n.ID]; ok {
+			continue
+		}
+		if checks == maxWithdrawnChecks || ctx.Err() != nil {
+			break
+		}
+		checks++
+
+		switch err := checkNoticePage(ctx, n.Link); {
+		case errors.Is(err, ErrNoticeGone):
+			withdrawn = append(withdrawn, n)
+		case err != nil:
+			logger.L().Warn("checking missing notice",
+				slog.String("link", n.Link),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return withdrawn
+}
+
+// owns reports whether n was cached from the listing of s.
+func (s SourceConfig) owns(n Notice) bool {
+	return n.category() == s.Category
+}
+
+// checkNoticePage requests the page at link, returning ErrNoticeGone if it
+// no longer exists.
+func checkNoticePage(ctx context.Context, link string) error {
+	response, err := httpGetWithRetry(ctx, link, currentRetryPolicy())
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+	if pageGone(response.StatusCode) {
+		return fmt.Errorf("%w: received status code %d", ErrNoticeGone, response.StatusCode)
+	}
+	return nil
+}
+
+func pageGone(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone
+}
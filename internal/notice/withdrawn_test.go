@@ -0,0 +1,73 @@
+package notice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_findWithdrawn(t *testing.T) {
+	withTempDataDir(t)
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path == "/retracted" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	notice := func(path string, date time.Time, category string) Notice {
+		link := server.URL + path
+		return Notice{ID: NoticeID(link), Link: link, Title: path, Date: date, Category: category}
+	}
+
+	listed := []Notice{notice("/new", day(10), "Notices"), notice("/undated", time.Time{}, "Notices"), notice("/kept", day(5), "Notices")}
+	retracted := notice("/retracted", day(8), "Notices")
+	reordered := notice("/reordered", day(6), "Notices")
+	// published the same day as the oldest listed notice, now on the next page
+	nextPage := notice("/next-page", day(5), "Notices")
+	agedOff := notice("/aged-off", day(1), "Notices")
+	otherSource := notice("/news", day(9), "News")
+
+	cache := append([]Notice{retracted, reordered, nextPage, agedOff, otherSource}, listed...)
+	if _, err := mergeCachedNotices(cache, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	withdrawn := findWithdrawn(context.Background(), DefaultSource, listed)
+	if len(withdrawn) != 1 || withdrawn[0].ID != retracted.ID {
+		t.Fatalf("withdrawn = %+v, want only %s", withdrawn, retracted.Link)
+	}
+	if len(requested) != 2 {
+		t.Errorf("expected detail checks for the two missing recent notices, got %v", requested)
+	}
+
+	if _, err := mergeCachedNotices(listed, withdrawn); err != nil {
+		t.Fatal(err)
+	}
+	flagged := func() bool {
+		cached, _ := GetCachedNotices()
+		for _, n := range cached {
+			if n.ID == retracted.ID {
+				return n.Withdrawn()
+			}
+		}
+		return false
+	}
+	if !flagged() {
+		t.Fatal("retracted notice not flagged in the cache")
+	}
+
+	// A withdrawn notice that is listed again is restored.
+	if _, err := mergeCachedNotices([]Notice{retracted}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if flagged() {
+		t.Error("relisted notice still flagged as withdrawn")
+	}
+}
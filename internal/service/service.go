@@ -3,45 +3,59 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/AtifChy/aiub-notice/internal/common"
+	"github.com/AtifChy/aiub-notice/internal/config"
 	"github.com/AtifChy/aiub-notice/internal/logger"
 	"github.com/AtifChy/aiub-notice/internal/notice"
 	"github.com/AtifChy/aiub-notice/internal/toast"
 )
 
-// Run starts the notice checking service.
-func Run(checkInterval time.Duration) {
+// Run starts the notice checking service for the sources in cfg and blocks
+// until ctx is cancelled. Cancelling ctx also aborts any in-flight request or
+// retry wait, so the service stops promptly even during a slow check.
+func Run(ctx context.Context, cfg *config.Config, checkInterval time.Duration) error {
+	if checkInterval <= 0 {
+		return fmt.Errorf("invalid check interval %s", checkInterval)
+	}
+
 	logger.L().Info("starting initial notice check...")
 
-	// Load previously seen notices
-	seenNotices, err := notice.LoadSeenNotices()
+	store, err := notice.DefaultStore()
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+
+	// Load previously seen notices. Without them every listed notice would
+	// be announced again, so the service does not start.
+	seenNotices, err := notice.LoadSeen(store)
 	if err != nil {
-		logger.L().Error("loading seen notices", slog.String("error", err.Error()))
-		seenNotices = make(map[string]struct{})
+		return fmt.Errorf("load seen notices: %w", err)
+	}
+
+	var syncer *notice.Syncer
+	if cfg.SyncDir != "" {
+		syncer = notice.NewSyncer(store, cfg.SyncDir)
 	}
 
 	// Perform initial check for notices
-	if err = checkNotice(seenNotices); err != nil {
+	pullState(syncer, store, &seenNotices)
+	if err = checkNotice(ctx, cfg, store, &seenNotices); err != nil && ctx.Err() == nil {
 		logger.L().Error(
 			"initial notice check",
 			slog.String("error", err.Error()),
 		)
 	}
-
-	// Context for graceful shutdown
-	ctx, stop := signal.NotifyContext(
-		context.Background(),
-		os.Interrupt,
-	)
-	defer stop()
+	pushState(syncer)
+	maintainStore(cfg, store, &seenNotices)
+	lastMaintenance := time.Now()
 
 	// Start ticker for periodic checks
 	ticker := time.NewTicker(checkInterval)
@@ -54,40 +68,228 @@ func Run(checkInterval time.Duration) {
 		select {
 		case <-ticker.C:
 			logger.L().Info("checking for new notices...")
-			if err := checkNotice(seenNotices); err != nil {
+			pullState(syncer, store, &seenNotices)
+			if err := checkNotice(ctx, cfg, store, &seenNotices); err != nil && ctx.Err() == nil {
 				logger.L().Error("checking for new notices", slog.String("error", err.Error()))
 			}
+			pushState(syncer)
+			if time.Since(lastMaintenance) >= maintenanceInterval {
+				maintainStore(cfg, store, &seenNotices)
+				lastMaintenance = time.Now()
+			}
 
 		case <-ctx.Done():
 			logger.L().Info("received shutdown signal, stopping service...")
-			return
+			return nil
 		}
 	}
 }
 
-func checkNotice(seenNotices map[string]struct{}) error {
-	notices, err := notice.GetNotices()
+// maintenanceInterval is how often the service backs up and prunes the
+// store.
+const maintenanceInterval = 24 * time.Hour
+
+// maintainStore saves a backup of the store to restore from if it gets
+// damaged, then prunes it by the configured retention policy and compacts
+// it. Nothing is pruned unless the backup succeeded. seen is reloaded to
+// drop the IDs of the pruned notices.
+func maintainStore(cfg *config.Config, store notice.Store, seen *notice.SeenSet) {
+	if err := store.Backup(); err != nil {
+		logger.L().Error("backing up store", slog.String("error", err.Error()))
+		return
+	}
+	logger.L().Info("backed up store")
+
+	if cfg.Retention.IsZero() {
+		return
+	}
+	pruned, err := notice.Prune(store, cfg.Retention, time.Now())
+	if err != nil {
+		logger.L().Error("pruning store", slog.String("error", err.Error()))
+		return
+	}
+	if len(pruned) == 0 {
+		return
+	}
+	logger.L().Info("pruned old notices", slog.Int("count", len(pruned)))
+
+	if reloaded, err := notice.LoadSeen(store); err != nil {
+		logger.L().Warn("reloading seen notices", slog.String("error", err.Error()))
+	} else {
+		*seen = reloaded
+	}
+	if err := store.Compact(); err != nil {
+		logger.L().Error("compacting store", slog.String("error", err.Error()))
+	}
+}
+
+// pullState merges the state of the other machines sharing the sync folder
+// before a check, so notices they already announced are not announced again.
+// seen is reloaded if it changed.
+func pullState(syncer *notice.Syncer, store notice.Store, seen *notice.SeenSet) {
+	if syncer == nil {
+		return
+	}
+	stats, err := syncer.Pull()
+	if err != nil {
+		logger.L().Error("merging synced state", slog.String("error", err.Error()))
+	}
+	if stats.IsZero() {
+		return
+	}
+	logger.L().Info("merged synced state",
+		slog.Int("notices", stats.Notices),
+		slog.Int("seen", stats.Seen),
+		slog.Int("read", stats.Read),
+	)
+	if reloaded, err := notice.LoadSeen(store); err != nil {
+		logger.L().Warn("reloading seen notices", slog.String("error", err.Error()))
+	} else {
+		*seen = reloaded
+	}
+}
+
+// pushState shares the state of this machine through the sync folder after
+// a check.
+func pushState(syncer *notice.Syncer) {
+	if syncer == nil {
+		return
+	}
+	if err := syncer.Push(); err != nil {
+		logger.L().Error("writing synced state", slog.String("error", err.Error()))
+	}
+}
+
+func checkNotice(ctx context.Context, cfg *config.Config, store notice.Store, seenNotices *notice.SeenSet) error {
+	// Nothing was seen before the first check, so there is nothing to
+	// announce the notices found by it against.
+	firstRun := seenNotices.IsEmpty()
+
+	var notices, updatedNotices []notice.Notice
+	var errs []error
+	sources := cfg.EnabledSources()
+	if len(sources) == 0 {
+		return errors.New("no notice sources are enabled")
+	}
+	known, err := notice.PrimedCategories(store)
 	if err != nil {
-		return fmt.Errorf("fetch notices: %w", err)
+		logger.L().Warn("loading primed categories", slog.String("error", err.Error()))
+	}
+	for _, srcCfg := range sources {
+		src, err := notice.NewSource(srcCfg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// A category never fetched before is primed: its current notices are
+		// recorded as seen without notifying, so enabling a category does not
+		// flood the user with its whole first page.
+		_, primed := known[srcCfg.Category]
+		seen := *seenNotices
+		if !primed {
+			seen = notice.SeenSet{}
+		}
+
+		result, err := src.Fetch(ctx, seen)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fetch notices from %s: %w", srcCfg.Name, err))
+			continue
+		}
+		if !result.Changed() {
+			logger.L().Info("notice source unchanged",
+				slog.String("source", result.Source),
+				slog.String("status", string(result.Status)),
+			)
+			updated, err := notice.CacheResult(result)
+			if err != nil {
+				logger.L().Warn("caching notices", slog.String("error", err.Error()))
+			}
+			updatedNotices = append(updatedNotices, updated...)
+			continue
+		}
+		logger.L().Info("notice source changed",
+			slog.String("source", result.Source),
+			slog.String("status", string(result.Status)),
+			slog.Int("count", len(result.Notices)),
+			slog.Int("pages", result.Pages),
+		)
+
+		updated, err := notice.CacheResult(result)
+		if err != nil {
+			logger.L().Warn("caching notices", slog.String("error", err.Error()))
+		}
+		notifyWithdrawn(cfg, result.Withdrawn)
+		if !primed {
+			if err := notice.MarkPrimed(store, srcCfg.Category); err != nil {
+				logger.L().Warn("saving primed category", slog.String("error", err.Error()))
+			}
+		}
+
+		if !primed && !seenNotices.IsEmpty() {
+			logger.L().Info("priming new notice source",
+				slog.String("source", srcCfg.Name),
+				slog.Int("count", len(result.Notices)),
+			)
+			ids := make([]string, 0, len(result.Notices))
+			for _, n := range result.Notices {
+				seenNotices.Add(n.ID)
+				ids = append(ids, n.ID)
+			}
+			if err := store.MarkSeen(ids...); err != nil {
+				return fmt.Errorf("save seen notices: %w", err)
+			}
+			continue
+		}
+		notices = append(notices, result.Notices...)
+		updatedNotices = append(updatedNotices, updated...)
+	}
+	if len(errs) == len(sources) {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		logger.L().Error("fetching notices", slog.String("error", err.Error()))
 	}
 
 	var newNotices []notice.Notice
+	var newIDList []string
+	newIDs := make(map[string]struct{})
 	for _, n := range notices {
-		if _, seen := seenNotices[n.Link]; !seen {
+		if !seenNotices.Has(n) {
 			newNotices = append(newNotices, n)
-			seenNotices[n.Link] = struct{}{}
+			seenNotices.Add(n.ID)
+			newIDs[n.ID] = struct{}{}
+			newIDList = append(newIDList, n.ID)
+		}
+	}
+
+	// Notices edited after they were seen are announced once per change;
+	// a notice listed by several sources is only announced once.
+	for _, n := range updatedNotices {
+		if _, isNew := newIDs[n.ID]; isNew {
+			continue
+		}
+		newIDs[n.ID] = struct{}{}
+		if err := toast.ShowUpdated(n); err != nil {
+			logger.L().Error(
+				"showing toast notification",
+				slog.String("title", n.Title),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			logger.L().Info("sent notification for updated notice",
+				slog.String("title", n.Title),
+				slog.String("id", n.ID),
+			)
 		}
 	}
 
 	if len(newNotices) > 0 {
 		logger.L().Info("found new notices", slog.Int("count", len(newNotices)))
 
-		path, err := notice.GetSeenNoticesPath()
-		if err != nil {
-			logger.L().Error("getting seen notices path", slog.String("error", err.Error()))
-		}
-
-		if _, err = os.Stat(path); err == nil {
+		if firstRun {
+			logger.L().Warn("no notices were seen before, skipping notifications")
+		} else {
 			for _, n := range newNotices {
 				err := toast.Show(n)
 				if err != nil {
@@ -100,13 +302,9 @@ func checkNotice(seenNotices map[string]struct{}) error {
 					logger.L().Info("sent notification for notice", slog.String("title", n.Title))
 				}
 			}
-		} else if os.IsNotExist(err) {
-			logger.L().Warn("seen notices file does not exist, skipping notifications")
-		} else {
-			logger.L().Error("checking seen notices file", slog.String("error", err.Error()))
 		}
 
-		if err := notice.SaveSeenNotices(seenNotices); err != nil {
+		if err := store.MarkSeen(newIDList...); err != nil {
 			return fmt.Errorf("save seen notices: %w", err)
 		}
 	} else {
@@ -116,6 +314,27 @@ func checkNotice(seenNotices map[string]struct{}) error {
 	return nil
 }
 
+// notifyWithdrawn logs the notices found to have been taken down and
+// announces them if enabled in the configuration.
+func notifyWithdrawn(cfg *config.Config, withdrawn []notice.Notice) {
+	for _, n := range withdrawn {
+		logger.L().Info("notice was withdrawn",
+			slog.String("title", n.Title),
+			slog.String("id", n.ID),
+		)
+		if !cfg.NotifyWithdrawn {
+			continue
+		}
+		if err := toast.ShowWithdrawn(n); err != nil {
+			logger.L().Error(
+				"showing toast notification",
+				slog.String("title", n.Title),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
 func GetProcessFromLock() (*os.Process, error) {
 	lockPath, err := common.GetLockPath()
 	if err != nil {
@@ -8,18 +8,44 @@ import (
 	"github.com/AtifChy/aiub-notice/internal/notice"
 )
 
+// bodyLimit keeps the toast body short enough to fit a notification.
+const bodyLimit = 200
+
+// Show notifies about a new notice.
 func Show(notice notice.Notice) error {
+	return push(notice, title(notice))
+}
+
+// ShowUpdated notifies that a notice has been edited since it was seen.
+func ShowUpdated(notice notice.Notice) error {
+	return push(notice, "Updated: "+title(notice))
+}
+
+// ShowWithdrawn notifies that a notice has been taken down from the site.
+func ShowWithdrawn(notice notice.Notice) error {
+	return push(notice, "Withdrawn: "+title(notice))
+}
+
+func push(notice notice.Notice, title string) error {
 	notif := toast.Notification{
 		AppID:               common.AppID,
-		Title:               notice.Title,
-		Body:                notice.Desc,
+		Title:               title,
+		Body:                notice.Summary(bodyLimit),
 		ActivationType:      toast.Protocol,
-		ActivationArguments: notice.Link,
+		ActivationArguments: OpenURI(notice.ID, notice.Link),
 		Actions: []toast.Action{
-			{Type: toast.Protocol, Content: "Open", Arguments: notice.Link},
+			{Type: toast.Protocol, Content: "Open", Arguments: OpenURI(notice.ID, notice.Link)},
 			{Type: toast.Protocol, Content: "Dismiss", Arguments: ""},
 		},
 	}
 
 	return notif.Push()
 }
+
+// title prefixes the notice title with its category, if known.
+func title(n notice.Notice) string {
+	if n.Category == "" {
+		return n.Title
+	}
+	return "[" + n.Category + "] " + n.Title
+}
@@ -0,0 +1,38 @@
+package toast
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/AtifChy/aiub-notice/internal/common"
+)
+
+// OpenURI returns the URI that opens a notice from a toast. It is handled
+// by the open command, which marks the notice as read before opening its
+// link.
+func OpenURI(id, link string) string {
+	query := url.Values{"id": {id}, "url": {link}}
+	return common.URLScheme + ":open?" + query.Encode()
+}
+
+// ParseOpenURI returns the notice ID and link of a URI made by OpenURI. Only
+// http and https links are accepted.
+func ParseOpenURI(uri string) (id, link string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", fmt.Errorf("parse URI: %w", err)
+	}
+	if u.Scheme != common.URLScheme || u.Opaque != "open" {
+		return "", "", fmt.Errorf("not an open URI: %q", uri)
+	}
+	query := u.Query()
+	id, link = query.Get("id"), query.Get("url")
+	if id == "" || link == "" {
+		return "", "", fmt.Errorf("incomplete open URI: %q", uri)
+	}
+	// Any web page can open the URI, so it must not launch local files.
+	if !common.IsWebURL(link) {
+		return "", "", fmt.Errorf("open URI does not link to a web page: %q", uri)
+	}
+	return id, link, nil
+}
@@ -0,0 +1,25 @@
+package toast
+
+import "testing"
+
+func Test_ParseOpenURI(t *testing.T) {
+	link := "https://www.aiub.edu/notice?id=7&lang=en#top"
+	id, got, err := ParseOpenURI(OpenURI("3fa9c2e1d4b5a6c7", link))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "3fa9c2e1d4b5a6c7" || got != link {
+		t.Errorf("ParseOpenURI() = %q, %q", id, got)
+	}
+
+	for _, uri := range []string{
+		"https://www.aiub.edu/notice",
+		"aiub-notice:star?id=3fa9c2e1d4b5a6c7",
+		"aiub-notice:open?id=3fa9c2e1d4b5a6c7",
+		OpenURI("3fa9c2e1d4b5a6c7", `file:///C:/Windows/System32/calc.exe`),
+	} {
+		if _, _, err := ParseOpenURI(uri); err == nil {
+			t.Errorf("ParseOpenURI(%q) accepted", uri)
+		}
+	}
+}